@@ -0,0 +1,307 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// ErrRegression is returned when current results are worse than baseline beyond tolerance
+var ErrRegression = errors.New("performance regression detected")
+
+const (
+	DefaultTolerance      float64 = 10 // Percent of relative change for qps and response times
+	DefaultErrorTolerance float64 = 1  // Percentage points of failed rate change
+)
+
+// CompareConfig defines allowed degradation of current results against baseline
+type CompareConfig struct {
+	Tolerance      float64
+	ErrorTolerance float64
+}
+
+// ScenarioReport is scenario report read from JSON report file or taken from finished run
+type ScenarioReport struct {
+	Name   string  `json:"name"`
+	Report *Report `json:"report"`
+}
+
+// ScenarioComparison holds deltas of one scenario present in both baseline and current results
+type ScenarioComparison struct {
+	Name   string
+	Deltas []*MetricDelta
+
+	// Percentiles of current results which baseline doesn't have, they are not compared
+	Missing []string
+}
+
+// MetricDelta is change of one metric, Delta is in percent for qps and response times
+// and in percentage points for failed rate
+type MetricDelta struct {
+	Metric     string
+	Baseline   string
+	Current    string
+	Delta      float64
+	Regression bool
+}
+
+type compareMetric struct {
+	name         string
+	value        func(r *Report) (float64, error)
+	format       func(r *Report) string
+	higherBetter bool
+	absolute     bool // Compare absolute difference instead of relative one
+}
+
+var (
+	qpsMetric        = &compareMetric{name: "qps", value: func(r *Report) (float64, error) { return strconv.ParseFloat(r.QPS, 64) }, format: func(r *Report) string { return r.QPS }, higherBetter: true}
+	maxMetric        = &compareMetric{name: "max", value: func(r *Report) (float64, error) { return parseReportDuration(r.RespMax) }, format: func(r *Report) string { return r.RespMax }}
+	failedRateMetric = &compareMetric{name: "failed_rate", value: func(r *Report) (float64, error) { return parseReportPercent(r.FailedRate) }, format: func(r *Report) string { return r.FailedRate }, absolute: true}
+)
+
+// Get metrics compared for scenario, percentiles are compared only if both reports have them,
+// names of percentiles missing in baseline are returned separately
+func getCompareMetrics(base, cur *Report) ([]*compareMetric, []string) {
+	metrics := []*compareMetric{qpsMetric}
+	var missing []string
+	for _, p := range cur.Percentiles {
+		if findPercentile(base.Percentiles, p.Name) == nil {
+			missing = append(missing, p.Name)
+			continue
+		}
+		name := p.Name
+		value := func(r *Report) string { return findPercentile(r.Percentiles, name).Value }
+		metrics = append(metrics, &compareMetric{
+			name:   name,
+			value:  func(r *Report) (float64, error) { return parseReportDuration(value(r)) },
+			format: value,
+		})
+	}
+	return append(metrics, maxMetric, failedRateMetric), missing
+}
+
+func findPercentile(percentiles []*Percentile, name string) *Percentile {
+	for _, p := range percentiles {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// ReadReportFile reads scenario reports from JSON report written by GenerateReport
+func ReadReportFile(path string) ([]*ScenarioReport, error) {
+	// #nosec G304 -- path to report is provided by user on purpose
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Workflow struct {
+			Scenarios []*ScenarioReport `json:"scenarios"`
+		} `json:"workflow"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse report (%s): %w", path, err)
+	}
+	if len(file.Workflow.Scenarios) == 0 {
+		return nil, fmt.Errorf("report (%s) has no scenarios", path)
+	}
+	for _, sc := range file.Workflow.Scenarios {
+		if sc.Report == nil {
+			return nil, fmt.Errorf("report (%s) has no results for scenario %q", path, sc.Name)
+		}
+	}
+	if err := readLegacyPercentiles(data, file.Workflow.Scenarios); err != nil {
+		return nil, fmt.Errorf("failed to parse report (%s): %w", path, err)
+	}
+	return file.Workflow.Scenarios, nil
+}
+
+// Fill percentiles of reports written before percentiles became configurable,
+// such reports have only p50_resp_time, p90_resp_time and p95_resp_time keys
+func readLegacyPercentiles(data []byte, scenarios []*ScenarioReport) error {
+	var file struct {
+		Workflow struct {
+			Scenarios []struct {
+				Report struct {
+					P50 string `json:"p50_resp_time"`
+					P90 string `json:"p90_resp_time"`
+					P95 string `json:"p95_resp_time"`
+				} `json:"report"`
+			} `json:"scenarios"`
+		} `json:"workflow"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	for idx, sc := range scenarios {
+		if len(sc.Report.Percentiles) > 0 {
+			continue
+		}
+		legacy := file.Workflow.Scenarios[idx].Report
+		for _, p := range []*Percentile{{Name: "p50", Value: legacy.P50}, {Name: "p90", Value: legacy.P90}, {Name: "p95", Value: legacy.P95}} {
+			if p.Value != "" {
+				sc.Report.Percentiles = append(sc.Report.Percentiles, p)
+			}
+		}
+	}
+	return nil
+}
+
+// GetScenarioReports gets scenario reports of finished run
+func GetScenarioReports(cfg *RunConfig) []*ScenarioReport {
+	reports := make([]*ScenarioReport, 0, len(cfg.WorkflowConfig.Scenarios))
+	for _, sc := range cfg.WorkflowConfig.Scenarios {
+		if sc.Report != nil {
+			reports = append(reports, &ScenarioReport{Name: sc.Name, Report: sc.Report})
+		}
+	}
+	return reports
+}
+
+// CompareReports matches scenarios by name and calculates deltas of current results against baseline.
+// Scenarios missing in baseline or current results are skipped.
+func CompareReports(baseline, current []*ScenarioReport, cfg *CompareConfig) ([]*ScenarioComparison, error) {
+	byName := make(map[string]*Report, len(baseline))
+	for _, sc := range baseline {
+		byName[sc.Name] = sc.Report
+	}
+
+	comparisons := make([]*ScenarioComparison, 0, len(current))
+	for _, sc := range current {
+		base, ok := byName[sc.Name]
+		if !ok {
+			continue
+		}
+		metrics, missing := getCompareMetrics(base, sc.Report)
+		comparison := &ScenarioComparison{Name: sc.Name, Missing: missing}
+		for _, cm := range metrics {
+			delta, err := cm.compare(base, sc.Report, cfg)
+			if err != nil {
+				return nil, fmt.Errorf("scenario %q: %w", sc.Name, err)
+			}
+			comparison.Deltas = append(comparison.Deltas, delta)
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons, nil
+}
+
+func (cm *compareMetric) compare(base, cur *Report, cfg *CompareConfig) (*MetricDelta, error) {
+	baseValue, err := cm.value(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", cm.name, err)
+	}
+	curValue, err := cm.value(cur)
+	if err != nil {
+		return nil, fmt.Errorf("invalid current %s: %w", cm.name, err)
+	}
+
+	res := &MetricDelta{Metric: cm.name, Baseline: cm.format(base), Current: cm.format(cur)}
+	if cm.absolute {
+		res.Delta = curValue - baseValue
+		res.Regression = res.Delta > cfg.ErrorTolerance
+		return res, nil
+	}
+
+	if baseValue != 0 {
+		res.Delta = (curValue - baseValue) / baseValue * 100
+	}
+	if cm.higherBetter {
+		res.Regression = -res.Delta > cfg.Tolerance
+	} else {
+		res.Regression = res.Delta > cfg.Tolerance
+	}
+	return res, nil
+}
+
+// HasRegression checks if any delta is flagged as regression
+func HasRegression(comparisons []*ScenarioComparison) bool {
+	for _, sc := range comparisons {
+		for _, d := range sc.Deltas {
+			if d.Regression {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PrintComparison prints comparison table per scenario, regressions are highlighted
+func PrintComparison(w io.Writer, baseline, current []*ScenarioReport, comparisons []*ScenarioComparison) {
+	bold := color.New(color.Bold).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Fprint(w, bold("\n========== LoadHound Comparison ==========\n"))
+	for _, sc := range comparisons {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, bold(fmt.Sprintf("Name: %s", sc.Name)))
+		fmt.Fprintf(w, "%-12s %14s %14s %10s\n", "metric", "baseline", "current", "delta")
+		for _, d := range sc.Deltas {
+			unit := "%"
+			if d.Metric == "failed_rate" {
+				unit = "pp"
+			}
+			status := green("OK")
+			if d.Regression {
+				status = red("REGRESSION")
+			}
+			fmt.Fprintf(w, "%-12s %14s %14s %+9.2f%s %s\n", d.Metric, d.Baseline, d.Current, d.Delta, unit, status)
+		}
+		for _, name := range sc.Missing {
+			fmt.Fprintln(w, yellow(fmt.Sprintf("%s is missing in baseline, not compared", name)))
+		}
+	}
+
+	for _, name := range unmatchedScenarios(baseline, current) {
+		fmt.Fprintln(w, yellow(fmt.Sprintf("scenario %q is missing in current results", name)))
+	}
+	for _, name := range unmatchedScenarios(current, baseline) {
+		fmt.Fprintln(w, yellow(fmt.Sprintf("scenario %q is missing in baseline", name)))
+	}
+}
+
+// Get names of scenarios from a which are absent in b
+func unmatchedScenarios(a, b []*ScenarioReport) []string {
+	names := make(map[string]struct{}, len(b))
+	for _, sc := range b {
+		names[sc.Name] = struct{}{}
+	}
+	var missing []string
+	for _, sc := range a {
+		if _, ok := names[sc.Name]; !ok {
+			missing = append(missing, sc.Name)
+		}
+	}
+	return missing
+}
+
+func parseReportDuration(s string) (float64, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return float64(d), nil
+}
+
+func parseReportPercent(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+}
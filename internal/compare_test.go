@@ -0,0 +1,186 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompareReport(qps, p95, failedRate string) *Report {
+	return &Report{
+		QPS: qps,
+		Percentiles: []*Percentile{
+			{Name: "p50", Value: "1ms"},
+			{Name: "p90", Value: "2ms"},
+			{Name: "p95", Value: p95},
+		},
+		RespMax:    "10ms",
+		FailedRate: failedRate,
+	}
+}
+
+func TestCompareReports(t *testing.T) {
+	cfg := &CompareConfig{Tolerance: DefaultTolerance, ErrorTolerance: DefaultErrorTolerance}
+	baseline := []*ScenarioReport{
+		{Name: "select", Report: newCompareReport("1000.00", "3ms", "0.00%")},
+		{Name: "insert", Report: newCompareReport("500.00", "5ms", "0.50%")},
+		{Name: "removed", Report: newCompareReport("100.00", "5ms", "0.00%")},
+	}
+
+	t.Run("should not flag changes within tolerance", func(t *testing.T) {
+		current := []*ScenarioReport{
+			{Name: "select", Report: newCompareReport("950.00", "3.2ms", "0.90%")},
+		}
+
+		comparisons, err := CompareReports(baseline, current, cfg)
+
+		require.NoError(t, err)
+		require.Len(t, comparisons, 1)
+		assert.Equal(t, "select", comparisons[0].Name)
+		metrics := make([]string, 0, len(comparisons[0].Deltas))
+		for _, d := range comparisons[0].Deltas {
+			metrics = append(metrics, d.Metric)
+		}
+		assert.Equal(t, []string{"qps", "p50", "p90", "p95", "max", "failed_rate"}, metrics)
+		assert.InDelta(t, -5.0, comparisons[0].Deltas[0].Delta, 0.001)
+		assert.False(t, HasRegression(comparisons))
+	})
+
+	t.Run("should flag regressions", func(t *testing.T) {
+		current := []*ScenarioReport{
+			{Name: "select", Report: newCompareReport("850.00", "3ms", "0.00%")},
+			{Name: "insert", Report: newCompareReport("500.00", "6ms", "2.00%")},
+			{Name: "added", Report: newCompareReport("100.00", "5ms", "0.00%")},
+		}
+
+		comparisons, err := CompareReports(baseline, current, cfg)
+
+		require.NoError(t, err)
+		require.Len(t, comparisons, 2)
+		regressions := map[string][]string{}
+		for _, sc := range comparisons {
+			for _, d := range sc.Deltas {
+				if d.Regression {
+					regressions[sc.Name] = append(regressions[sc.Name], d.Metric)
+				}
+			}
+		}
+		assert.Equal(t, map[string][]string{"select": {"qps"}, "insert": {"p95", "failed_rate"}}, regressions)
+		assert.True(t, HasRegression(comparisons))
+
+		var out bytes.Buffer
+		PrintComparison(&out, baseline, current, comparisons)
+		assert.Contains(t, out.String(), "REGRESSION")
+		assert.Contains(t, out.String(), `scenario "removed" is missing in current results`)
+		assert.Contains(t, out.String(), `scenario "added" is missing in baseline`)
+	})
+
+	t.Run("should warn about percentiles missing in baseline", func(t *testing.T) {
+		report := newCompareReport("1000.00", "3ms", "0.00%")
+		report.Percentiles = append(report.Percentiles, &Percentile{Name: "p99.9", Value: "1s"})
+		current := []*ScenarioReport{{Name: "select", Report: report}}
+
+		comparisons, err := CompareReports(baseline, current, cfg)
+
+		require.NoError(t, err)
+		require.Len(t, comparisons, 1)
+		assert.Len(t, comparisons[0].Deltas, 6)
+		assert.Equal(t, []string{"p99.9"}, comparisons[0].Missing)
+		assert.False(t, HasRegression(comparisons))
+
+		var out bytes.Buffer
+		PrintComparison(&out, baseline, current, comparisons)
+		assert.Contains(t, out.String(), "p99.9 is missing in baseline, not compared")
+	})
+
+	t.Run("should return error for invalid report value", func(t *testing.T) {
+		current := []*ScenarioReport{
+			{Name: "select", Report: newCompareReport("950.00", "fast", "0.00%")},
+		}
+
+		_, err := CompareReports(baseline, current, cfg)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid current p95")
+	})
+}
+
+func TestReadReportFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("should read report written by GenerateReport", func(t *testing.T) {
+		cfg := &RunConfig{
+			DbConfig: &DbConfig{Driver: "postgres", Dsn: "postgres://localhost/db"},
+			WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+				Name:            "select",
+				Duration:        time.Minute,
+				Threads:         1,
+				StatementConfig: &StatementConfig{Query: "SELECT 1"},
+				Report:          newCompareReport("1000.00", "3ms", "0.00%"),
+			}}},
+		}
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		require.NoError(t, err)
+		path := filepath.Join(dir, "report.json")
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		reports, err := ReadReportFile(path)
+
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		assert.Equal(t, "select", reports[0].Name)
+		assert.Equal(t, "1000.00", reports[0].Report.QPS)
+		assert.Equal(t, GetScenarioReports(cfg), reports)
+	})
+
+	t.Run("should read percentiles of report written before percentiles were configurable", func(t *testing.T) {
+		path := filepath.Join(dir, "legacy.json")
+		legacy := `{"workflow":{"scenarios":[{"name":"select","report":{"qps":"1000.00","max_resp_time":"10ms","failed_rate":"0.00%",` +
+			`"p50_resp_time":"1ms","p90_resp_time":"2ms","p95_resp_time":"3ms"}}]}}`
+		require.NoError(t, os.WriteFile(path, []byte(legacy), 0600))
+
+		reports, err := ReadReportFile(path)
+
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		assert.Equal(t, newCompareReport("1000.00", "3ms", "0.00%").Percentiles, reports[0].Report.Percentiles)
+
+		comparisons, err := CompareReports(reports, []*ScenarioReport{{Name: "select", Report: newCompareReport("1000.00", "6ms", "0.00%")}}, &CompareConfig{Tolerance: DefaultTolerance})
+		require.NoError(t, err)
+		assert.True(t, HasRegression(comparisons), "p95 of legacy baseline is compared")
+		assert.Empty(t, comparisons[0].Missing)
+	})
+
+	t.Run("should return error for report without results", func(t *testing.T) {
+		path := filepath.Join(dir, "empty.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"workflow":{"scenarios":[{"name":"select"}]}}`), 0600))
+
+		_, err := ReadReportFile(path)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no results")
+	})
+
+	t.Run("should return error for invalid file", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		require.NoError(t, os.WriteFile(path, []byte(`not json`), 0600))
+
+		_, err := ReadReportFile(path)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse report")
+	})
+}
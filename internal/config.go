@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,9 +23,31 @@ import (
 // RunConfig represents the top-level test configuration.
 // It combines database, workflow, and output settings.
 type RunConfig struct {
-	DbConfig       *DbConfig       `toml:"db" json:"db"`
-	WorkflowConfig *WorkflowConfig `toml:"workflow" json:"workflow"`
-	OutputConfig   *OutputConfig   `toml:"output" json:"output"`
+	DbConfig         *DbConfig         `toml:"db" json:"db"`
+	WorkflowConfig   *WorkflowConfig   `toml:"workflow" json:"workflow"`
+	OutputConfig     *OutputConfig     `toml:"output" json:"output"`
+	ThresholdsConfig *ThresholdsConfig `toml:"thresholds" json:"thresholds"` // Thresholds applied to every scenario
+	PoolStats        *PoolStats        `json:"conn_pool_stats,omitempty"`    // Connection pool is shared by all scenarios
+}
+
+// ThresholdsConfig holds pass/fail expressions evaluated against scenario results,
+// e.g. "p95 < 50ms", "failed_rate < 1%", "qps > 200".
+// Abort expressions are checked while the test runs, the test is stopped when one is not met.
+type ThresholdsConfig struct {
+	Checks      []string      `toml:"checks" json:"checks"`
+	Abort       []string      `toml:"abort" json:"abort"`               // e.g. "failed_rate < 20%", "errors < 1000"
+	AbortWindow time.Duration `toml:"abort_window" json:"abort_window"` // Window for latency, rate and qps abort checks
+}
+
+func (tc *ThresholdsConfig) MarshalJSON() ([]byte, error) {
+	type AliasTC ThresholdsConfig
+	return json.Marshal(&struct {
+		AbortWindow string `json:"abort_window"`
+		*AliasTC
+	}{
+		AbortWindow: tc.AbortWindow.String(),
+		AliasTC:     (*AliasTC)(tc),
+	})
 }
 
 // DbConfig defines settings required to connect to the database.
@@ -59,37 +82,153 @@ func (cp *ConnPoolCfg) MarshalJSON() ([]byte, error) {
 // Each scenario defines a unique load testing pattern.
 type WorkflowConfig struct {
 	Scenarios []*ScenarioConfig `toml:"scenarios" json:"scenarios"`
+	Seed      *uint64           `toml:"seed" json:"seed,omitempty"` // Makes random query arguments of all scenarios reproducible
 }
 
 // ScenarioConfig defines one specific load testing scenario.
-// Either Duration or Iterations must be set (but not both).
+// Exactly one of Duration, Iterations or Stages must be set.
+// If Rate is set, scenario runs in open model: queries are started at a fixed
+// (or ramping with RampUp) rate by a pool of Threads workers.
 type ScenarioConfig struct {
-	Name            string           `toml:"name" json:"name"`             // Scenario name
-	Iterations      int              `toml:"iterations" json:"iterations"` // Number of iterations per thread
-	Duration        time.Duration    `toml:"duration" json:"duration"`     // Total duration of the scenario
-	Threads         int              `toml:"threads" json:"threads"`       // Number of concurrent threads
-	Pacing          time.Duration    `toml:"pacing" json:"pacing"`         // Delay between thread iterations
-	RampUp          time.Duration    `toml:"ramp_up" json:"ramp_up"`       // Time to ramp from 0 to N threads
-	StatementConfig *StatementConfig `toml:"statement" json:"statement"`   // SQL statement to execute
-	Report          *Report          `json:"report"`
+	Name             string             `toml:"name" json:"name"`               // Scenario name
+	Iterations       int                `toml:"iterations" json:"iterations"`   // Number of iterations per thread
+	Duration         time.Duration      `toml:"duration" json:"duration"`       // Total duration of the scenario
+	Threads          int                `toml:"threads" json:"threads"`         // Number of concurrent threads
+	Pacing           time.Duration      `toml:"pacing" json:"pacing"`           // Delay between thread iterations
+	RampUp           time.Duration      `toml:"ramp_up" json:"ramp_up"`         // Time to ramp from 0 to N threads
+	Stages           []*StageConfig     `toml:"stages" json:"stages"`           // Multi-stage load profile
+	Rate             int                `toml:"rate" json:"rate"`               // Arrivals per second for open-model scenario
+	StatementConfig  *StatementConfig   `toml:"statement" json:"statement"`     // SQL statement to execute
+	Statements       []*StatementConfig `toml:"statements" json:"statements"`   // SQL statements executed in sequence on each iteration
+	TxConfig         *TxConfig          `toml:"transaction" json:"transaction"` // Run each iteration inside database transaction
+	ThresholdsConfig *ThresholdsConfig  `toml:"thresholds" json:"thresholds"`   // Thresholds applied to this scenario only
+	Feeders          []*FeederConfig    `toml:"feeders" json:"feeders"`         // Files with rows used as query arguments
+	Seed             *uint64            `toml:"seed" json:"seed,omitempty"`     // Overrides workflow seed for this scenario
+	Report           *Report            `json:"report"`
+}
+
+// FeederConfig describes CSV or JSONL file whose columns are bound to placeholders with "feed <name> <column>" args.
+type FeederConfig struct {
+	Name        string `toml:"name" json:"name"`                 // Referenced in statement args
+	Path        string `toml:"path" json:"path"`                 // Path to CSV file with header or JSONL file
+	Format      string `toml:"format" json:"format"`             // csv or jsonl, detected by file extension if empty
+	Mode        string `toml:"mode" json:"mode"`                 // sequential (default), random or unique
+	Scope       string `toml:"scope" json:"scope"`               // shared (default) cursor for all threads or thread partitions
+	OnExhausted string `toml:"on_exhausted" json:"on_exhausted"` // stop (default) or recycle when unique feeder has no unused rows
+}
+
+// StageConfig defines one step of a multi-stage load profile.
+// During the stage the number of running threads changes linearly
+// from the previous stage target (or 0) to Target.
+type StageConfig struct {
+	Target   int           `toml:"target" json:"target"`     // Number of threads at the end of the stage
+	Duration time.Duration `toml:"duration" json:"duration"` // Duration of the stage
+}
+
+func (st *StageConfig) MarshalJSON() ([]byte, error) {
+	type AliasST StageConfig
+	return json.Marshal(&struct {
+		Duration string `json:"duration"`
+		*AliasST
+	}{
+		Duration: st.Duration.String(),
+		AliasST:  (*AliasST)(st),
+	})
 }
 
 type Report struct {
-	Duration          string   `json:"scenario_duration"`
-	ThreadsTotal      int64    `json:"threads_total"`
-	IterationsTotal   int64    `json:"iterations_total"`
-	QueriesTotal      int64    `json:"queries_total"`
-	QPS               string   `json:"qps"`
-	RespMin           string   `json:"min_resp_time"`
-	RespMax           string   `json:"max_resp_time"`
-	SuccessRate       string   `json:"success_rate"`
-	FailedRate        string   `json:"failed_rate"`
-	P50               string   `json:"p50_resp_time"`
-	P90               string   `json:"p90_resp_time"`
-	P95               string   `json:"p95_resp_time"`
-	RowsAffectedTotal int64    `json:"affected_rows"`
-	ErrCount          int64    `json:"err_total"`
-	TopErrors         []string `json:"top_errors"`
+	Duration          string             `json:"scenario_duration"`
+	ThreadsTotal      int64              `json:"threads_total"`
+	IterationsTotal   int64              `json:"iterations_total"`
+	QueriesTotal      int64              `json:"queries_total"`
+	QPS               string             `json:"qps"`
+	RespMin           string             `json:"min_resp_time"`
+	RespMax           string             `json:"max_resp_time"`
+	MeanResp          string             `json:"mean_resp_time"`
+	StdDevResp        string             `json:"stddev_resp_time"`
+	SuccessRate       string             `json:"success_rate"`
+	FailedRate        string             `json:"failed_rate"`
+	Percentiles       []*Percentile      `json:"percentiles"`
+	RowsAffectedTotal int64              `json:"affected_rows"`
+	ErrCount          int64              `json:"err_total"`
+	SlowTotal         int64              `json:"slow_queries_total"`
+	Errors            []*ErrorReport     `json:"errors"`
+	TopErrors         []string           `json:"top_errors"` // Samples of the most frequent classes, kept for consumers of reports without classes
+	Thresholds        []*ThresholdResult `json:"thresholds,omitempty"`
+	Aborted           bool               `json:"aborted"`
+	AbortReason       string             `json:"abort_reason,omitempty"`
+	Interrupted       bool               `json:"interrupted"`
+	SerializationErrs int64              `json:"serialization_failures_total"`
+	DeadlockErrs      int64              `json:"deadlocks_total"`
+	DroppedArrivals   int64              `json:"dropped_arrivals"`
+	DelayedArrivals   int64              `json:"delayed_arrivals"`
+
+	// Time arrivals waited for free worker, set only for open-model scenarios
+	QueueDelayMax         string        `json:"queue_delay_max,omitempty"`
+	QueueDelayPercentiles []*Percentile `json:"queue_delay_percentiles,omitempty"`
+
+	// Response time measured from intended iteration start, set only with hdr recorder
+	CorrectedMax         string        `json:"corrected_max_resp_time,omitempty"`
+	CorrectedMean        string        `json:"corrected_mean_resp_time,omitempty"`
+	CorrectedPercentiles []*Percentile `json:"corrected_percentiles,omitempty"`
+
+	// Set only for scenarios with several statements
+	IterPercentiles []*Percentile      `json:"iter_percentiles,omitempty"`
+	Statements      []*StatementReport `json:"statements,omitempty"`
+
+	// Results per time bucket, in order of time
+	Timeseries []*TimeseriesPoint `json:"timeseries"`
+}
+
+// ErrorReport holds count of errors of one class with the first distinct messages of this class as samples.
+type ErrorReport struct {
+	Class   string   `json:"class"`
+	Count   int64    `json:"count"`
+	Samples []string `json:"samples"`
+}
+
+// Percentile holds response time at configured percentile, e.g. name "p99.9".
+type Percentile struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// TimeseriesPoint holds scenario results of one time bucket, latencies are in milliseconds.
+type TimeseriesPoint struct {
+	Time          time.Time `json:"time"` // Start of the bucket
+	QPS           float64   `json:"qps"`
+	Queries       int64     `json:"queries"`
+	Errors        int64     `json:"errors"`
+	RowsAffected  int64     `json:"affected_rows"`
+	ActiveThreads int       `json:"active_threads"`
+	Max           float64   `json:"max_ms"`
+
+	// Latencies at configured percentiles, in configured order
+	Percentiles []*TimeseriesPercentile `json:"percentiles"`
+
+	// Errors per second by class
+	ErrorRates map[string]float64 `json:"error_rates,omitempty"`
+}
+
+// TimeseriesPercentile holds latency of time bucket at configured percentile in milliseconds, e.g. name "p99.9".
+type TimeseriesPercentile struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value_ms"`
+}
+
+// StatementReport holds results of one statement of multi-statement scenario.
+type StatementReport struct {
+	Name              string        `json:"name"`
+	QueriesTotal      int64         `json:"queries_total"`
+	RespMin           string        `json:"min_resp_time"`
+	RespMax           string        `json:"max_resp_time"`
+	MeanResp          string        `json:"mean_resp_time"`
+	StdDevResp        string        `json:"stddev_resp_time"`
+	FailedRate        string        `json:"failed_rate"`
+	Percentiles       []*Percentile `json:"percentiles"`
+	RowsAffectedTotal int64         `json:"affected_rows"`
+	ErrCount          int64         `json:"err_total"`
+	SlowTotal         int64         `json:"slow_queries_total"`
 }
 
 func (sc *ScenarioConfig) MarshalJSON() ([]byte, error) {
@@ -107,24 +246,129 @@ func (sc *ScenarioConfig) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// GetStatements returns statements executed on each iteration,
+// either single statement or ordered statements list.
+func (sc *ScenarioConfig) GetStatements() []*StatementConfig {
+	if len(sc.Statements) > 0 {
+		return sc.Statements
+	}
+	return []*StatementConfig{sc.StatementConfig}
+}
+
+// TxConfig defines database transaction which wraps statements of each iteration.
+type TxConfig struct {
+	IsolationLevel string `toml:"isolation_level" json:"isolation_level"` // "read_committed", "repeatable_read", "serializable"...
+	ReadOnly       bool   `toml:"read_only" json:"read_only"`             // Start read-only transaction
+	End            string `toml:"end" json:"end"`                         // "commit" (default) or "rollback"
+}
+
 // StatementConfig holds the SQL query definition used by each scenario.
 type StatementConfig struct {
 	Name        string `toml:"name" json:"name"`                   // Optional label
 	PathToQuery string `toml:"path_to_query" json:"path_to_query"` // Path to file which contains query
 	Query       string `toml:"query" json:"query"`                 // SQL query text
 	Args        string `toml:"args" json:"args"`                   // Optional arguments for parameterized queries
+
+	Timeout       time.Duration `toml:"timeout" json:"timeout"`               // Optional deadline of each query
+	SlowThreshold time.Duration `toml:"slow_threshold" json:"slow_threshold"` // Queries slower than threshold are counted and logged
+}
+
+func (stc *StatementConfig) MarshalJSON() ([]byte, error) {
+	type AliasSTC StatementConfig
+	return json.Marshal(&struct {
+		Timeout       string `json:"timeout"`
+		SlowThreshold string `json:"slow_threshold"`
+		*AliasSTC
+	}{
+		Timeout:       stc.Timeout.String(),
+		SlowThreshold: stc.SlowThreshold.String(),
+		AliasSTC:      (*AliasSTC)(stc),
+	})
 }
 
 // OutputConfig specifies how test results are reported and logged.
 type OutputConfig struct {
-	ReportConfig *ReportConfig `toml:"report" json:"report"`
-	LogConfig    *LogConfig    `toml:"log" json:"log"`
+	ReportConfig     *ReportConfig     `toml:"report" json:"report"`
+	LogConfig        *LogConfig        `toml:"log" json:"log"`
+	ProgressConfig   *ProgressConfig   `toml:"progress" json:"progress"`
+	PrometheusConfig *PrometheusConfig `toml:"prometheus" json:"prometheus"`
+	LineSinkConfig   *LineSinkConfig   `toml:"line_protocol" json:"line_protocol"`
+	SamplesConfig    *SamplesConfig    `toml:"samples" json:"samples"`
 }
 
-// ReportConfig defines whether the test report should be printed to console, written to file, or both.
+// SamplesConfig defines raw log with one record per query result for offline analysis.
+type SamplesConfig struct {
+	Format      string  `toml:"format" json:"format"`             // "csv" (default) or "ndjson", ndjson is gzip compressed
+	File        string  `toml:"file" json:"file"`                 // default "loadhound_samples_<time>.csv" or ".ndjson.gz"
+	SampleRate  float64 `toml:"sample_rate" json:"sample_rate"`   // Fraction of results written, default 1 (all)
+	IncludeArgs bool    `toml:"include_args" json:"include_args"` // Write bound query arguments
+}
+
+// LineSinkConfig defines periodic push of scenario aggregates in InfluxDB line protocol
+// or Graphite plaintext format. At least one of URL or File must be set.
+type LineSinkConfig struct {
+	Format      string        `toml:"format" json:"format"`           // "influx" (default) or "graphite"
+	URL         string        `toml:"url" json:"url"`                 // http(s)://... for Influx write API, tcp://host:port for socket
+	Token       string        `toml:"token" json:"-"`                 // Optional Influx API token
+	File        string        `toml:"file" json:"file"`               // Local file, e.g. "metrics.lp"
+	Measurement string        `toml:"measurement" json:"measurement"` // Measurement name or Graphite prefix, default "loadhound"
+	Interval    time.Duration `toml:"interval" json:"interval"`       // How often aggregates are pushed, default 10s
+}
+
+func (lc *LineSinkConfig) MarshalJSON() ([]byte, error) {
+	type AliasLC LineSinkConfig
+	return json.Marshal(&struct {
+		Interval string `json:"interval"`
+		*AliasLC
+	}{
+		Interval: lc.Interval.String(),
+		AliasLC:  (*AliasLC)(lc),
+	})
+}
+
+// PrometheusConfig defines HTTP endpoint which exposes live metrics for Prometheus scraping.
+type PrometheusConfig struct {
+	Listen string `toml:"listen" json:"listen"` // e.g. ":9091"
+	Path   string `toml:"path" json:"path"`     // default "/metrics"
+}
+
+// ProgressConfig defines periodic summary of running scenarios printed to console.
+type ProgressConfig struct {
+	ToConsole bool          `toml:"to_console" json:"to_console"`
+	Interval  time.Duration `toml:"interval" json:"interval"` // How often summary is printed, default 5s
+}
+
+func (pc *ProgressConfig) MarshalJSON() ([]byte, error) {
+	type AliasPC ProgressConfig
+	return json.Marshal(&struct {
+		Interval string `json:"interval"`
+		*AliasPC
+	}{
+		Interval: pc.Interval.String(),
+		AliasPC:  (*AliasPC)(pc),
+	})
+}
+
+// ReportConfig defines whether the test report should be printed to console, written to JSON, HTML or JUnit XML file.
 type ReportConfig struct {
-	ToFile    bool `toml:"to_file" json:"to_file"`
-	ToConsole bool `toml:"to_console" json:"to_console"`
+	ToFile             bool          `toml:"to_file" json:"to_file"`
+	ToConsole          bool          `toml:"to_console" json:"to_console"`
+	ToHTML             bool          `toml:"to_html" json:"to_html"`                         // Save self-contained HTML report with charts
+	ToJUnit            bool          `toml:"to_junit" json:"to_junit"`                       // Save JUnit XML report with thresholds as test cases
+	TimeseriesInterval time.Duration `toml:"timeseries_interval" json:"timeseries_interval"` // Time-series bucket size, default 1s
+	Percentiles        []float64     `toml:"percentiles" json:"percentiles"`                 // Reported response time percentiles, default [50, 90, 95, 99]
+	Recorder           string        `toml:"recorder" json:"recorder"`                       // "tdigest" (default) or "hdr" with coordinated omission correction
+}
+
+func (rc *ReportConfig) MarshalJSON() ([]byte, error) {
+	type AliasRC ReportConfig
+	return json.Marshal(&struct {
+		TimeseriesInterval string `json:"timeseries_interval"`
+		*AliasRC
+	}{
+		TimeseriesInterval: rc.TimeseriesInterval.String(),
+		AliasRC:            (*AliasRC)(rc),
+	})
 }
 
 // LogConfig defines logging behavior and destination.
@@ -145,12 +389,14 @@ func GetConfig(path string) (*RunConfig, error) {
 
 	// Save query from file into field 'query' in statement config
 	for _, sc := range cfg.WorkflowConfig.Scenarios {
-		if sc.StatementConfig.PathToQuery != "" {
-			data, err := os.ReadFile(sc.StatementConfig.PathToQuery)
-			if err != nil {
-				return nil, err
+		for _, stmt := range sc.GetStatements() {
+			if stmt.PathToQuery != "" {
+				data, err := os.ReadFile(stmt.PathToQuery)
+				if err != nil {
+					return nil, err
+				}
+				stmt.Query = string(data)
 			}
-			sc.StatementConfig.Query = string(data)
 		}
 	}
 	return &cfg, nil
@@ -203,37 +449,307 @@ func validateConfig(cfg *RunConfig) error {
 		return errors.New("non scenarios set for test")
 	}
 
+	if err := validateThresholds(cfg.ThresholdsConfig, hdrEnabled(cfg)); err != nil {
+		return err
+	}
+	if cfg.OutputConfig != nil {
+		if err := validateProgress(cfg.OutputConfig.ProgressConfig); err != nil {
+			return err
+		}
+		if err := validateReport(cfg.OutputConfig.ReportConfig); err != nil {
+			return err
+		}
+		if err := validateLineSink(cfg.OutputConfig.LineSinkConfig); err != nil {
+			return err
+		}
+		if err := validateSamples(cfg.OutputConfig.SamplesConfig); err != nil {
+			return err
+		}
+		if prom := cfg.OutputConfig.PrometheusConfig; prom != nil {
+			if prom.Listen == "" {
+				return errors.New("prometheus listen address is empty")
+			}
+			if prom.Path != "" && !strings.HasPrefix(prom.Path, "/") {
+				return fmt.Errorf("prometheus path: (%s) must start with '/'", prom.Path)
+			}
+		}
+	}
+
 	// Range and validate in scenarios configuration list
 	for _, sc := range cfg.WorkflowConfig.Scenarios {
-		dur := sc.Duration
-		iter := sc.Iterations
-		pacing := sc.Pacing
-		if dur == 0 && iter == 0 {
-			return fmt.Errorf("either duration: (%v) or iteration: (%d) must be set", dur, iter)
+		if err := validateThresholds(sc.ThresholdsConfig, hdrEnabled(cfg)); err != nil {
+			return err
 		}
-		if dur > 0 && iter > 0 {
-			return fmt.Errorf("duration: (%v) and iteration: (%d) are mutual exclusion - specify only one", dur, iter)
+		if len(sc.Stages) > 0 {
+			if err := validateStages(sc); err != nil {
+				return err
+			}
+		} else if err := validateScenarioLoad(sc); err != nil {
+			return err
 		}
-		if dur > 0 && pacing > dur {
-			return fmt.Errorf("pacing: (%v) cannot be more than test duration: (%v)", pacing, dur)
+		if err := validateRate(sc); err != nil {
+			return err
 		}
-		if sc.Threads <= 0 {
-			return errors.New("threads count must be >= 1")
+		if sc.TxConfig != nil {
+			if _, err := getTxOptions(sc.TxConfig); err != nil {
+				return err
+			}
+			if sc.TxConfig.End != "" && sc.TxConfig.End != txEndCommit && sc.TxConfig.End != txEndRollback {
+				return fmt.Errorf("transaction end: (%s) must be either %q or %q", sc.TxConfig.End, txEndCommit, txEndRollback)
+			}
 		}
 
 		// Validate scenarios statement config
-		if sc.StatementConfig == nil {
+		if sc.StatementConfig != nil && len(sc.Statements) > 0 {
+			return errors.New("statement and statements are mutual exclusion - specify only one")
+		}
+		if sc.StatementConfig == nil && len(sc.Statements) == 0 {
 			return errors.New("statement is nil")
 		}
+		for _, stmt := range sc.GetStatements() {
+			if err := validateStatement(stmt); err != nil {
+				return err
+			}
+		}
+		if err := validateFeeders(sc.Feeders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate statement query source
+func validateStatement(stmt *StatementConfig) error {
+	if stmt == nil {
+		return errors.New("statement is nil")
+	}
+	if stmt.Query == "" && stmt.PathToQuery == "" {
+		return errors.New("query is empty")
+	}
+	if stmt.Query != "" && stmt.PathToQuery != "" {
+		return fmt.Errorf("query: (%s) and path to file with query: (%s) are mutual exclusion - specify only one",
+			stmt.Query, stmt.PathToQuery)
+	}
+	if stmt.Timeout < 0 {
+		return fmt.Errorf("statement timeout: (%v) cannot be negative", stmt.Timeout)
+	}
+	if stmt.SlowThreshold < 0 {
+		return fmt.Errorf("statement slow_threshold: (%v) cannot be negative", stmt.SlowThreshold)
+	}
+	return nil
+}
+
+// Validate duration or iterations based scenario load
+func validateScenarioLoad(sc *ScenarioConfig) error {
+	dur := sc.Duration
+	iter := sc.Iterations
+	pacing := sc.Pacing
+	if dur == 0 && iter == 0 {
+		return fmt.Errorf("either duration: (%v) or iteration: (%d) must be set", dur, iter)
+	}
+	if dur > 0 && iter > 0 {
+		return fmt.Errorf("duration: (%v) and iteration: (%d) are mutual exclusion - specify only one", dur, iter)
+	}
+	if dur > 0 && pacing > dur {
+		return fmt.Errorf("pacing: (%v) cannot be more than test duration: (%v)", pacing, dur)
+	}
+	if sc.Threads <= 0 {
+		return errors.New("threads count must be >= 1")
+	}
+	return nil
+}
+
+// Validate multi-stage load profile
+func validateStages(sc *ScenarioConfig) error {
+	if sc.Duration > 0 || sc.Iterations > 0 {
+		return errors.New("stages are mutual exclusion with duration and iterations - specify only one")
+	}
+	if sc.RampUp > 0 {
+		return errors.New("stages are mutual exclusion with ramp_up - use a stage instead")
+	}
+	for idx, st := range sc.Stages {
+		if st == nil {
+			return fmt.Errorf("stage #%d is nil", idx+1)
+		}
+		if st.Target < 0 {
+			return fmt.Errorf("stage #%d: target: (%d) cannot be negative", idx+1, st.Target)
+		}
+		if st.Duration <= 0 {
+			return fmt.Errorf("stage #%d: duration: (%v) must be > 0", idx+1, st.Duration)
+		}
+	}
+	if maxStageTarget(sc.Stages) == 0 {
+		return errors.New("at least one stage must have target >= 1")
+	}
+	if sc.Threads > 0 && sc.Threads < maxStageTarget(sc.Stages) {
+		return fmt.Errorf("threads: (%d) cannot be less than max stage target: (%d)", sc.Threads, maxStageTarget(sc.Stages))
+	}
+	if dur := stagesDuration(sc.Stages); sc.Pacing > dur {
+		return fmt.Errorf("pacing: (%v) cannot be more than stages duration: (%v)", sc.Pacing, dur)
+	}
+	return nil
+}
+
+// Validate open-model (arrival-rate) scenario settings
+func validateRate(sc *ScenarioConfig) error {
+	if sc.Rate < 0 {
+		return fmt.Errorf("rate: (%d) cannot be negative", sc.Rate)
+	}
+	if sc.Rate == 0 {
+		return nil
+	}
+	if sc.Duration == 0 || len(sc.Stages) > 0 {
+		return errors.New("rate requires duration and cannot be used with iterations or stages")
+	}
+	if sc.Pacing > 0 {
+		return fmt.Errorf("pacing: (%v) cannot be used with rate", sc.Pacing)
+	}
+	return nil
+}
+
+// Validate progress interval, progress is printed at most once per monitor tick
+func validateProgress(cfg *ProgressConfig) error {
+	if cfg == nil || cfg.Interval == 0 {
+		return nil
+	}
+	if cfg.Interval < monitorInterval {
+		return fmt.Errorf("progress interval: (%v) must be at least %v", cfg.Interval, monitorInterval)
+	}
+	return nil
+}
+
+func validateReport(cfg *ReportConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.TimeseriesInterval != 0 && (cfg.TimeseriesInterval < monitorInterval || cfg.TimeseriesInterval%monitorInterval != 0) {
+		return fmt.Errorf("timeseries_interval: (%v) must be a whole number of seconds", cfg.TimeseriesInterval)
+	}
+	for _, p := range cfg.Percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("percentiles: (%v) must be greater than 0 and not greater than 100", p)
+		}
+	}
+	switch cfg.Recorder {
+	case "", recorderTDigest, recorderHdr:
+	default:
+		return fmt.Errorf("recorder: (%s) is not supported, expected tdigest or hdr", cfg.Recorder)
+	}
+	return nil
+}
 
-		// Validate statement query source
-		if sc.StatementConfig.Query == "" && sc.StatementConfig.PathToQuery == "" {
-			return errors.New("query is empty")
+func validateLineSink(cfg *LineSinkConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.URL == "" && cfg.File == "" {
+		return errors.New("line_protocol: url or file must be set")
+	}
+	switch cfg.Format {
+	case "", lineFormatInflux, lineFormatGraphite:
+	default:
+		return fmt.Errorf("line_protocol format: (%s) is not supported, expected influx or graphite", cfg.Format)
+	}
+	if cfg.URL != "" {
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return fmt.Errorf("line_protocol url: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "tcp":
+		default:
+			return fmt.Errorf("line_protocol url: (%s) must use http, https or tcp scheme", cfg.URL)
 		}
-		if sc.StatementConfig.Query != "" && sc.StatementConfig.PathToQuery != "" {
-			return fmt.Errorf("query: (%s) and path to file with query: (%s) are mutual exclusion - specify only one",
-				sc.StatementConfig.Query, sc.StatementConfig.PathToQuery)
+		if u.Host == "" {
+			return fmt.Errorf("line_protocol url: (%s) has no host", cfg.URL)
 		}
 	}
+	if cfg.Interval != 0 && (cfg.Interval < monitorInterval || cfg.Interval%monitorInterval != 0) {
+		return fmt.Errorf("line_protocol interval: (%v) must be a whole number of seconds", cfg.Interval)
+	}
+	return nil
+}
+
+func validateSamples(cfg *SamplesConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.Format {
+	case "", samplesFormatCSV, samplesFormatNDJSON:
+	default:
+		return fmt.Errorf("samples format: (%s) is not supported, expected csv or ndjson", cfg.Format)
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return fmt.Errorf("samples sample_rate: (%v) must be between 0 and 1", cfg.SampleRate)
+	}
+	return nil
+}
+
+func validateFeeders(cfgs []*FeederConfig) error {
+	names := make(map[string]bool, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			return errors.New("feeder is nil")
+		}
+		if cfg.Name == "" {
+			return errors.New("feeder name is empty")
+		}
+		if names[cfg.Name] {
+			return fmt.Errorf("feeder name: (%s) is duplicated", cfg.Name)
+		}
+		names[cfg.Name] = true
+		if cfg.Path == "" {
+			return fmt.Errorf("feeder %s path is empty", cfg.Name)
+		}
+		switch feederFormat(cfg) {
+		case feederFormatCSV, feederFormatJSONL:
+		default:
+			return fmt.Errorf("feeder %s format: (%s) is not supported, expected csv or jsonl", cfg.Name, cfg.Format)
+		}
+		switch cfg.Mode {
+		case "", feederModeSequential, feederModeRandom, feederModeUnique:
+		default:
+			return fmt.Errorf("feeder %s mode: (%s) is not supported, expected sequential, random or unique", cfg.Name, cfg.Mode)
+		}
+		switch cfg.Scope {
+		case "", feederScopeShared, feederScopeThread:
+		default:
+			return fmt.Errorf("feeder %s scope: (%s) is not supported, expected shared or thread", cfg.Name, cfg.Scope)
+		}
+		switch cfg.OnExhausted {
+		case "", feederOnExhaustedStop, feederOnExhaustedRecycle:
+		default:
+			return fmt.Errorf("feeder %s on_exhausted: (%s) is not supported, expected stop or recycle", cfg.Name, cfg.OnExhausted)
+		}
+	}
+	return nil
+}
+
+// Validate threshold expressions, corrected latency is recorded only by hdr recorder and only for final checks
+func validateThresholds(cfg *ThresholdsConfig, hdr bool) error {
+	if cfg == nil {
+		return nil
+	}
+	for _, expr := range cfg.Checks {
+		th, err := ParseThreshold(expr)
+		if err != nil {
+			return err
+		}
+		if th.corrected && !hdr {
+			return fmt.Errorf("threshold: (%s) corrected latency requires hdr recorder", expr)
+		}
+	}
+	for _, expr := range cfg.Abort {
+		th, err := ParseThreshold(expr)
+		if err != nil {
+			return err
+		}
+		if th.corrected {
+			return fmt.Errorf("abort: (%s) corrected latency is not supported in abort checks", expr)
+		}
+	}
+	if cfg.AbortWindow < 0 {
+		return fmt.Errorf("abort_window: (%v) cannot be negative", cfg.AbortWindow)
+	}
 	return nil
 }
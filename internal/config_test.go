@@ -586,4 +586,439 @@ func TestValidateConfig_EdgeCases(t *testing.T) {
 		err := validateConfig(config)
 		assert.NoError(t, err)
 	})
+	t.Run("valid stages profile", func(t *testing.T) {
+		config := &RunConfig{
+			DbConfig: &DbConfig{
+				Driver: "postgres",
+				Dsn:    "user:pass@localhost/db",
+			},
+			WorkflowConfig: &WorkflowConfig{
+				Scenarios: []*ScenarioConfig{
+					{
+						Name: "test_scenario",
+						Stages: []*StageConfig{
+							{Target: 10, Duration: 10 * time.Second},
+							{Target: 10, Duration: 30 * time.Second},
+							{Target: 0, Duration: 10 * time.Second},
+						},
+						StatementConfig: &StatementConfig{
+							Name:  "select_test",
+							Query: "SELECT * FROM users",
+						},
+					},
+				},
+			},
+		}
+
+		err := validateConfig(config)
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid stages profile", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			sc       *ScenarioConfig
+			errorMsg string
+		}{
+			{
+				name:     "stages with duration",
+				sc:       &ScenarioConfig{Duration: time.Second, Stages: []*StageConfig{{Target: 1, Duration: time.Second}}},
+				errorMsg: "stages are mutual exclusion with duration and iterations",
+			},
+			{
+				name:     "stages with ramp_up",
+				sc:       &ScenarioConfig{RampUp: time.Second, Stages: []*StageConfig{{Target: 1, Duration: time.Second}}},
+				errorMsg: "stages are mutual exclusion with ramp_up",
+			},
+			{
+				name:     "negative target",
+				sc:       &ScenarioConfig{Stages: []*StageConfig{{Target: -1, Duration: time.Second}}},
+				errorMsg: "cannot be negative",
+			},
+			{
+				name:     "zero stage duration",
+				sc:       &ScenarioConfig{Stages: []*StageConfig{{Target: 1}}},
+				errorMsg: "must be > 0",
+			},
+			{
+				name:     "all targets are zero",
+				sc:       &ScenarioConfig{Stages: []*StageConfig{{Target: 0, Duration: time.Second}}},
+				errorMsg: "at least one stage must have target >= 1",
+			},
+			{
+				name:     "threads less than max target",
+				sc:       &ScenarioConfig{Threads: 2, Stages: []*StageConfig{{Target: 5, Duration: time.Second}}},
+				errorMsg: "cannot be less than max stage target",
+			},
+			{
+				name:     "pacing more than stages duration",
+				sc:       &ScenarioConfig{Pacing: 3 * time.Second, Stages: []*StageConfig{{Target: 1, Duration: time.Second}}},
+				errorMsg: "cannot be more than stages duration",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.sc.StatementConfig = &StatementConfig{Query: "SELECT 1"}
+				config := &RunConfig{
+					DbConfig:       &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{tt.sc}},
+				}
+
+				err := validateConfig(config)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
+	t.Run("rate settings", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			sc       *ScenarioConfig
+			errorMsg string
+		}{
+			{
+				name: "valid rate with duration",
+				sc:   &ScenarioConfig{Duration: time.Second, Threads: 10, Rate: 500, RampUp: 100 * time.Millisecond},
+			},
+			{
+				name:     "negative rate",
+				sc:       &ScenarioConfig{Duration: time.Second, Threads: 10, Rate: -1},
+				errorMsg: "cannot be negative",
+			},
+			{
+				name:     "rate with iterations",
+				sc:       &ScenarioConfig{Iterations: 10, Threads: 10, Rate: 500},
+				errorMsg: "rate requires duration",
+			},
+			{
+				name:     "rate with pacing",
+				sc:       &ScenarioConfig{Duration: time.Second, Threads: 10, Rate: 500, Pacing: time.Millisecond},
+				errorMsg: "cannot be used with rate",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.sc.StatementConfig = &StatementConfig{Query: "SELECT 1"}
+				config := &RunConfig{
+					DbConfig:       &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{tt.sc}},
+				}
+
+				err := validateConfig(config)
+				if tt.errorMsg == "" {
+					assert.NoError(t, err)
+					return
+				}
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
+	t.Run("statements list", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			sc       *ScenarioConfig
+			errorMsg string
+		}{
+			{
+				name: "valid statements list",
+				sc: &ScenarioConfig{Duration: time.Second, Threads: 1, Statements: []*StatementConfig{
+					{Name: "lock", Query: "SELECT * FROM accounts WHERE id = $1 FOR UPDATE", Args: "randIntRange 1 100"},
+					{Name: "audit", Query: "INSERT INTO audit (ts) VALUES (now())"},
+				}},
+			},
+			{
+				name: "statement and statements together",
+				sc: &ScenarioConfig{Duration: time.Second, Threads: 1,
+					StatementConfig: &StatementConfig{Query: "SELECT 1"},
+					Statements:      []*StatementConfig{{Query: "SELECT 2"}},
+				},
+				errorMsg: "statement and statements are mutual exclusion",
+			},
+			{
+				name:     "statement in list without query",
+				sc:       &ScenarioConfig{Duration: time.Second, Threads: 1, Statements: []*StatementConfig{{Query: "SELECT 1"}, {Name: "empty"}}},
+				errorMsg: "query is empty",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				config := &RunConfig{
+					DbConfig:       &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{tt.sc}},
+				}
+
+				err := validateConfig(config)
+				if tt.errorMsg == "" {
+					assert.NoError(t, err)
+					return
+				}
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
+	t.Run("transaction settings", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			tx       *TxConfig
+			errorMsg string
+		}{
+			{name: "valid transaction", tx: &TxConfig{IsolationLevel: "serializable", End: "rollback"}},
+			{name: "unknown isolation level", tx: &TxConfig{IsolationLevel: "chaos"}, errorMsg: "unknown transaction isolation_level"},
+			{name: "unknown end", tx: &TxConfig{End: "abort"}, errorMsg: "transaction end"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				config := &RunConfig{
+					DbConfig: &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+						Duration:        time.Second,
+						Threads:         1,
+						TxConfig:        tt.tx,
+						StatementConfig: &StatementConfig{Query: "SELECT 1"},
+					}}},
+				}
+
+				err := validateConfig(config)
+				if tt.errorMsg == "" {
+					assert.NoError(t, err)
+					return
+				}
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
+	t.Run("invalid thresholds", func(t *testing.T) {
+		config := &RunConfig{
+			DbConfig:         &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+			ThresholdsConfig: &ThresholdsConfig{Checks: []string{"p95 < 50ms"}},
+			WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+				Duration:         time.Second,
+				Threads:          1,
+				ThresholdsConfig: &ThresholdsConfig{Checks: []string{"p95 < fast"}},
+				StatementConfig:  &StatementConfig{Query: "SELECT 1"},
+			}}},
+		}
+
+		err := validateConfig(config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid threshold")
+	})
+	t.Run("invalid progress interval", func(t *testing.T) {
+		config := &RunConfig{
+			DbConfig:     &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+			OutputConfig: &OutputConfig{ProgressConfig: &ProgressConfig{ToConsole: true, Interval: 500 * time.Millisecond}},
+			WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+				Duration:        time.Second,
+				Threads:         1,
+				StatementConfig: &StatementConfig{Query: "SELECT 1"},
+			}}},
+		}
+
+		err := validateConfig(config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "progress interval")
+	})
+	t.Run("invalid timeseries interval", func(t *testing.T) {
+		for _, interval := range []time.Duration{500 * time.Millisecond, 1500 * time.Millisecond} {
+			config := &RunConfig{
+				DbConfig:     &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+				OutputConfig: &OutputConfig{ReportConfig: &ReportConfig{ToFile: true, TimeseriesInterval: interval}},
+				WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+					Duration:        time.Second,
+					Threads:         1,
+					StatementConfig: &StatementConfig{Query: "SELECT 1"},
+				}}},
+			}
+
+			err := validateConfig(config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "timeseries_interval")
+		}
+	})
+	t.Run("invalid line protocol sink", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			cfg      *LineSinkConfig
+			errorMsg string
+		}{
+			{"no destination", &LineSinkConfig{}, "url or file must be set"},
+			{"unknown format", &LineSinkConfig{File: "metrics.lp", Format: "json"}, "is not supported"},
+			{"unsupported scheme", &LineSinkConfig{URL: "udp://localhost:8089"}, "must use http, https or tcp"},
+			{"missing host", &LineSinkConfig{URL: "http:///write"}, "has no host"},
+			{"fractional interval", &LineSinkConfig{File: "metrics.lp", Interval: 1500 * time.Millisecond}, "whole number of seconds"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				config := &RunConfig{
+					DbConfig:     &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					OutputConfig: &OutputConfig{LineSinkConfig: tt.cfg},
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+						Duration:        time.Second,
+						Threads:         1,
+						StatementConfig: &StatementConfig{Query: "SELECT 1"},
+					}}},
+				}
+
+				err := validateConfig(config)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
+	t.Run("invalid percentiles", func(t *testing.T) {
+		for _, p := range []float64{0, -1, 100.1} {
+			config := &RunConfig{
+				DbConfig:     &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+				OutputConfig: &OutputConfig{ReportConfig: &ReportConfig{Percentiles: []float64{50, p}}},
+				WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+					Duration:        time.Second,
+					Threads:         1,
+					StatementConfig: &StatementConfig{Query: "SELECT 1"},
+				}}},
+			}
+
+			err := validateConfig(config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "percentiles")
+		}
+	})
+	t.Run("invalid recorder", func(t *testing.T) {
+		config := &RunConfig{
+			DbConfig:     &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+			OutputConfig: &OutputConfig{ReportConfig: &ReportConfig{Recorder: "histogram"}},
+			WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+				Duration:        time.Second,
+				Threads:         1,
+				StatementConfig: &StatementConfig{Query: "SELECT 1"},
+			}}},
+		}
+
+		err := validateConfig(config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "recorder: (histogram) is not supported")
+	})
+	t.Run("invalid samples", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			cfg      *SamplesConfig
+			errorMsg string
+		}{
+			{"unknown format", &SamplesConfig{Format: "parquet"}, "is not supported"},
+			{"negative sample rate", &SamplesConfig{SampleRate: -0.1}, "must be between 0 and 1"},
+			{"sample rate above one", &SamplesConfig{SampleRate: 1.5}, "must be between 0 and 1"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				config := &RunConfig{
+					DbConfig:     &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					OutputConfig: &OutputConfig{SamplesConfig: tt.cfg},
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+						Duration:        time.Second,
+						Threads:         1,
+						StatementConfig: &StatementConfig{Query: "SELECT 1"},
+					}}},
+				}
+
+				err := validateConfig(config)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
+	t.Run("invalid abort thresholds", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			cfg      *ThresholdsConfig
+			errorMsg string
+		}{
+			{"invalid expression", &ThresholdsConfig{Abort: []string{"failed_rate above 20%"}}, "invalid threshold"},
+			{"unknown metric", &ThresholdsConfig{Abort: []string{"latency < 20ms"}}, "unknown metric"},
+			{"negative window", &ThresholdsConfig{Abort: []string{"errors < 100"}, AbortWindow: -time.Second}, "abort_window"},
+			{"corrected latency", &ThresholdsConfig{Abort: []string{"corrected_p99 < 1s"}}, "not supported in abort checks"},
+			{"corrected latency without hdr", &ThresholdsConfig{Checks: []string{"corrected_p99 < 1s"}}, "requires hdr recorder"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				config := &RunConfig{
+					DbConfig:         &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					ThresholdsConfig: tt.cfg,
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+						Duration:        time.Second,
+						Threads:         1,
+						StatementConfig: &StatementConfig{Query: "SELECT 1"},
+					}}},
+				}
+
+				err := validateConfig(config)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
+	t.Run("invalid statement timeouts", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			stmt     *StatementConfig
+			errorMsg string
+		}{
+			{"negative timeout", &StatementConfig{Query: "SELECT 1", Timeout: -time.Second}, "statement timeout"},
+			{"negative slow threshold", &StatementConfig{Query: "SELECT 1", SlowThreshold: -time.Millisecond}, "slow_threshold"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				config := &RunConfig{
+					DbConfig: &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+						Duration:        time.Second,
+						Threads:         1,
+						StatementConfig: tt.stmt,
+					}}},
+				}
+
+				err := validateConfig(config)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
+	t.Run("invalid feeders", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			feeders  []*FeederConfig
+			errorMsg string
+		}{
+			{"empty name", []*FeederConfig{{Path: "ids.csv"}}, "feeder name is empty"},
+			{"duplicated name", []*FeederConfig{{Name: "ids", Path: "ids.csv"}, {Name: "ids", Path: "ids.jsonl"}}, "is duplicated"},
+			{"empty path", []*FeederConfig{{Name: "ids"}}, "feeder ids path is empty"},
+			{"unknown extension", []*FeederConfig{{Name: "ids", Path: "ids.txt"}}, "format: () is not supported"},
+			{"unknown format", []*FeederConfig{{Name: "ids", Path: "ids.csv", Format: "parquet"}}, "format: (parquet) is not supported"},
+			{"unknown mode", []*FeederConfig{{Name: "ids", Path: "ids.csv", Mode: "shuffle"}}, "mode: (shuffle)"},
+			{"unknown scope", []*FeederConfig{{Name: "ids", Path: "ids.csv", Scope: "scenario"}}, "scope: (scenario)"},
+			{"unknown on_exhausted", []*FeederConfig{{Name: "ids", Path: "ids.csv", OnExhausted: "wait"}}, "on_exhausted: (wait)"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				config := &RunConfig{
+					DbConfig: &DbConfig{Driver: "postgres", Dsn: "user:pass@localhost/db"},
+					WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+						Duration:        time.Second,
+						Threads:         1,
+						StatementConfig: &StatementConfig{Query: "SELECT 1"},
+						Feeders:         tt.feeders,
+					}}},
+				}
+
+				err := validateConfig(config)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			})
+		}
+	})
 }
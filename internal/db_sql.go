@@ -40,6 +40,31 @@ func (sa *SQLClient) Close() error {
 	return sa.DB.Close()
 }
 
+// Tx is a database transaction which statements of one iteration are executed in
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	StmtContext(ctx context.Context, stmt *sql.Stmt) *sql.Stmt
+	Commit() error
+	Rollback() error
+}
+
+func (sa *SQLClient) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	return sa.DB.BeginTx(ctx, opts)
+}
+
+type txCtxKey struct{}
+
+// ContextWithTx returns context which makes queries of SQLClient and PreparedStatement run inside tx
+func ContextWithTx(ctx context.Context, tx Tx) context.Context {
+	return context.WithValue(ctx, txCtxKey{}, tx)
+}
+
+func txFromContext(ctx context.Context) Tx {
+	tx, _ := ctx.Value(txCtxKey{}).(Tx)
+	return tx
+}
+
 type PreparedStatement struct {
 	stmt   *sql.Stmt
 	client *SQLClient
@@ -60,6 +85,14 @@ func (ps *PreparedStatement) Close() error {
 	return ps.stmt.Close()
 }
 
+// Get statement bound to transaction from context, statement is closed by transaction end
+func (ps *PreparedStatement) txStmt(ctx context.Context) *sql.Stmt {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.StmtContext(ctx, ps.stmt)
+	}
+	return ps.stmt
+}
+
 func setConnPoolParams(cfg *ConnPoolCfg, db *sql.DB) {
 	if cfg == nil {
 		return
@@ -82,15 +115,33 @@ type QueryResult struct {
 	Args         []any
 	Query        string
 	RowsAffected int64
+	StartTime    time.Time
 	ResponseTime time.Duration
 	Err          error
+
+	// How late iteration of this query started against its intended start by schedule
+	ScheduleDelay time.Duration
+
+	// Pacing of iterations, iterations missed while this query stalled are recorded as corrected results
+	ExpectedInterval time.Duration
+
+	// Set when query took longer than slow threshold of its statement
+	Slow bool
 }
 
 func (sa *SQLClient) ExecContext(ctx context.Context, query string) *QueryResult {
 	startTime := time.Now()
-	result, err := sa.DB.ExecContext(ctx, query)
+	var (
+		result sql.Result
+		err    error
+	)
+	if tx := txFromContext(ctx); tx != nil {
+		result, err = tx.ExecContext(ctx, query)
+	} else {
+		result, err = sa.DB.ExecContext(ctx, query)
+	}
 
-	queryResult := &QueryResult{Query: query, ResponseTime: time.Since(startTime)}
+	queryResult := &QueryResult{Query: query, StartTime: startTime, ResponseTime: time.Since(startTime)}
 	if err != nil {
 		queryResult.Err = err
 		return queryResult
@@ -106,9 +157,17 @@ func (sa *SQLClient) ExecContext(ctx context.Context, query string) *QueryResult
 
 func (sa *SQLClient) QueryContext(ctx context.Context, query string) *QueryResult {
 	startTime := time.Now()
-	rows, err := sa.DB.QueryContext(ctx, query)
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if tx := txFromContext(ctx); tx != nil {
+		rows, err = tx.QueryContext(ctx, query)
+	} else {
+		rows, err = sa.DB.QueryContext(ctx, query)
+	}
 
-	queryResult := &QueryResult{Query: query, ResponseTime: time.Since(startTime)}
+	queryResult := &QueryResult{Query: query, StartTime: startTime, ResponseTime: time.Since(startTime)}
 	if err != nil {
 		queryResult.Err = err
 		return queryResult
@@ -124,9 +183,9 @@ func (sa *SQLClient) QueryContext(ctx context.Context, query string) *QueryResul
 
 func (sa *PreparedStatement) StmtExecContext(ctx context.Context, query string, args ...any) *QueryResult {
 	startTime := time.Now()
-	result, err := sa.stmt.ExecContext(ctx, args...)
+	result, err := sa.txStmt(ctx).ExecContext(ctx, args...)
 
-	queryResult := &QueryResult{Query: query, Args: args, ResponseTime: time.Since(startTime)}
+	queryResult := &QueryResult{Query: query, Args: args, StartTime: startTime, ResponseTime: time.Since(startTime)}
 	if err != nil {
 		queryResult.Err = err
 		return queryResult
@@ -142,9 +201,9 @@ func (sa *PreparedStatement) StmtExecContext(ctx context.Context, query string,
 
 func (sa *PreparedStatement) StmtQueryContext(ctx context.Context, query string, args ...any) *QueryResult {
 	startTime := time.Now()
-	rows, err := sa.stmt.QueryContext(ctx, args...)
+	rows, err := sa.txStmt(ctx).QueryContext(ctx, args...)
 
-	queryResult := &QueryResult{Query: query, Args: args, ResponseTime: time.Since(startTime)}
+	queryResult := &QueryResult{Query: query, Args: args, StartTime: startTime, ResponseTime: time.Since(startTime)}
 	if err != nil {
 		queryResult.Err = err
 		return queryResult
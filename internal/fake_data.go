@@ -0,0 +1,271 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const fakeDateLayout = "2006-01-02"
+
+// Built-in word lists, so fake data works offline.
+// Cities have non-ASCII names on purpose, they matter for collation-sensitive columns.
+var (
+	fakeFirstNames = []string{
+		"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth",
+		"David", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+		"Daniel", "Nancy", "Matthew", "Lisa", "Anthony", "Betty", "Mark", "Margaret", "Donald", "Sandra",
+		"Ahmed", "Fatima", "Ivan", "Olga", "Hiroshi", "Yuki", "Carlos", "Lucia", "Pierre", "Amelie",
+		"Lars", "Ingrid", "Mateo", "Sofia", "Wei", "Mei", "Arjun", "Priya", "Ulukbek", "Aigerim",
+	}
+	fakeLastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+		"Lee", "Perez", "Thompson", "White", "Harris", "Sanchez", "Clark", "Ramirez", "Lewis", "Robinson",
+		"Muller", "Schmidt", "Ivanov", "Petrova", "Tanaka", "Suzuki", "Rossi", "Bianchi", "Dubois", "Laurent",
+		"Nielsen", "Hansen", "Silva", "Santos", "Wang", "Li", "Kumar", "Sharma", "Toichuev", "Abdieva",
+	}
+	fakeCities = []string{
+		"New York", "Los Angeles", "Chicago", "Houston", "Toronto", "Mexico City", "São Paulo", "Buenos Aires", "Bogotá", "Lima",
+		"London", "Paris", "Berlin", "Madrid", "Rome", "Zürich", "Kraków", "København", "Reykjavík", "Wien",
+		"Moscow", "Istanbul", "Cairo", "Lagos", "Nairobi", "Johannesburg", "Dubai", "Mumbai", "Delhi", "Bangkok",
+		"Beijing", "Shanghai", "Tokyo", "Seoul", "Singapore", "Jakarta", "Sydney", "Auckland", "Almaty", "Bishkek",
+	}
+	fakeCountryCodes = []string{
+		"US", "CA", "MX", "BR", "AR", "CO", "PE", "CL", "GB", "FR",
+		"DE", "ES", "IT", "CH", "PL", "DK", "IS", "AT", "NL", "SE",
+		"NO", "FI", "RU", "TR", "EG", "NG", "KE", "ZA", "AE", "IN",
+		"TH", "CN", "JP", "KR", "SG", "ID", "AU", "NZ", "KZ", "KG",
+	}
+	fakeEmailDomains = []string{"example.com", "example.org", "example.net", "mail.example", "test.example"}
+	fakeLoremWords   = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip",
+		"ex", "ea", "commodo", "consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate",
+		"velit", "esse", "cillum", "fugiat", "nulla", "pariatur", "excepteur", "sint", "occaecat", "cupidatat",
+		"non", "proident", "sunt", "culpa", "qui", "officia", "deserunt", "mollit", "anim", "id", "est", "laborum",
+	}
+)
+
+// Get fake data generator by its signature, false is returned if function is not a fake data generator
+func getFakeGenerator(funcSignature []string, feeders map[string]*Feeder, seqs *Sequences) (GeneratorFunc, bool, error) {
+	funcName, args := funcSignature[0], funcSignature[1:]
+	noArgs := func(gen GeneratorFunc) (GeneratorFunc, bool, error) {
+		if len(args) > 0 {
+			return nil, true, fmt.Errorf("invalid function signature, %s() does not support args: %v", funcName, funcSignature)
+		}
+		return gen, true, nil
+	}
+
+	switch funcName {
+	case "firstName":
+		return noArgs(func(gc *GenContext) any { return pick(gc.Rand, fakeFirstNames) })
+	case "lastName":
+		return noArgs(func(gc *GenContext) any { return pick(gc.Rand, fakeLastNames) })
+	case "email":
+		return noArgs(func(gc *GenContext) any { return FakeEmail(gc.Rand) })
+	case "phone":
+		return noArgs(func(gc *GenContext) any { return FakePhone(gc.Rand) })
+	case "city":
+		return noArgs(func(gc *GenContext) any { return pick(gc.Rand, fakeCities) })
+	case "countryCode":
+		return noArgs(func(gc *GenContext) any { return pick(gc.Rand, fakeCountryCodes) })
+	case "ipv4":
+		return noArgs(func(gc *GenContext) any { return FakeIPv4(gc.Rand) })
+	case "ipv6":
+		return noArgs(func(gc *GenContext) any { return FakeIPv6(gc.Rand) })
+
+	case "lorem":
+		if len(args) != 1 && len(args) != 2 {
+			return nil, true, fmt.Errorf("lorem() requires 1 or 2 arguments, got %d: %v", len(args), funcSignature)
+		}
+		parsedArgs, err := parseInt(args...)
+		if err != nil {
+			return nil, true, fmt.Errorf("lorem() argument parsing error: %w", err)
+		}
+		minWords, maxWords := parsedArgs[0], parsedArgs[len(parsedArgs)-1]
+		if minWords < 1 || maxWords < minWords {
+			return nil, true, fmt.Errorf("lorem() validation error: number of words must be positive and min %d cannot exceed max %d", minWords, maxWords)
+		}
+		return func(gc *GenContext) any {
+			return FakeLorem(gc.Rand, minWords+gc.Rand.IntN(maxWords-minWords+1))
+		}, true, nil
+
+	case "dateInRange":
+		if len(args) != 2 {
+			return nil, true, fmt.Errorf("dateInRange() requires exactly 2 arguments, got %d: %v", len(args), funcSignature)
+		}
+		from, err := time.Parse(fakeDateLayout, args[0])
+		if err != nil {
+			return nil, true, fmt.Errorf("dateInRange() argument parsing error: %w", err)
+		}
+		to, err := time.Parse(fakeDateLayout, args[1])
+		if err != nil {
+			return nil, true, fmt.Errorf("dateInRange() argument parsing error: %w", err)
+		}
+		if !from.Before(to) {
+			return nil, true, fmt.Errorf("dateInRange() validation error: from %s must be before to %s", args[0], args[1])
+		}
+		days := int(to.Sub(from) / (24 * time.Hour))
+		return func(gc *GenContext) any {
+			return from.AddDate(0, 0, gc.Rand.IntN(days+1)).Format(fakeDateLayout)
+		}, true, nil
+
+	case "money":
+		if len(args) != 2 {
+			return nil, true, fmt.Errorf("money() requires exactly 2 arguments, got %d: %v", len(args), funcSignature)
+		}
+		parsedArgs, err := parseFloat64(args...)
+		if err != nil {
+			return nil, true, fmt.Errorf("money() argument parsing error: %w", err)
+		}
+		minCents, maxCents, err := moneyCents(parsedArgs[0], parsedArgs[1])
+		if err != nil {
+			return nil, true, fmt.Errorf("money() validation error: %w", err)
+		}
+		return func(gc *GenContext) any { return FakeMoney(gc.Rand, minCents, maxCents) }, true, nil
+
+	case "jsonDoc":
+		gen, err := getJSONDocGenerator(args, feeders, seqs)
+		return gen, true, err
+	}
+	return nil, false, nil
+}
+
+// Get generator of JSON object with fields in configured order.
+// Field is "name:function" or "name:function:arg:arg" for function with arguments, e.g. "age:randIntRange:18:90".
+func getJSONDocGenerator(fields []string, feeders map[string]*Feeder, seqs *Sequences) (GeneratorFunc, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("jsonDoc() requires at least one field")
+	}
+	keys := make([][]byte, 0, len(fields))
+	values := make([]GeneratorFunc, 0, len(fields))
+	for _, field := range fields {
+		parts := strings.Split(field, ":")
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("jsonDoc() field %q must be in name:function format", field)
+		}
+		if parts[1] == "jsonDoc" {
+			return nil, fmt.Errorf("jsonDoc() field %q cannot be nested jsonDoc", field)
+		}
+		gens, err := GetGenerators(strings.Join(parts[1:], " "), feeders, seqs)
+		if err != nil {
+			return nil, fmt.Errorf("jsonDoc() field %q: %w", parts[0], err)
+		}
+		key, err := json.Marshal(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, gens[0])
+	}
+	return func(gc *GenContext) any {
+		var b bytes.Buffer
+		b.WriteByte('{')
+		for idx, gen := range values {
+			if idx > 0 {
+				b.WriteByte(',')
+			}
+			b.Write(keys[idx])
+			b.WriteByte(':')
+			value, err := json.Marshal(gen(gc))
+			if err != nil {
+				value = []byte("null")
+			}
+			b.Write(value)
+		}
+		b.WriteByte('}')
+		return b.String()
+	}, nil
+}
+
+func pick(r *rand.Rand, words []string) string {
+	return words[r.IntN(len(words))]
+}
+
+// FakeEmail gets address at reserved example domain, so it never reaches real mailbox
+func FakeEmail(r *rand.Rand) string {
+	return fmt.Sprintf("%s.%s%d@%s",
+		strings.ToLower(pick(r, fakeFirstNames)),
+		strings.ToLower(pick(r, fakeLastNames)),
+		r.IntN(1000),
+		pick(r, fakeEmailDomains))
+}
+
+// FakePhone gets phone number in E.164 format with NANP numbering
+func FakePhone(r *rand.Rand) string {
+	return fmt.Sprintf("+1%03d%03d%04d", 200+r.IntN(800), 200+r.IntN(800), r.IntN(10000))
+}
+
+func FakeIPv4(r *rand.Rand) string {
+	var ip [4]byte
+	v := r.Uint32()
+	ip[0], ip[1], ip[2], ip[3] = byte(v>>24), byte(v>>16), byte(v>>8), byte(v)
+	return netip.AddrFrom4(ip).String()
+}
+
+func FakeIPv6(r *rand.Rand) string {
+	var ip [16]byte
+	for i := 0; i < len(ip); i += 8 {
+		v := r.Uint64()
+		for j := 0; j < 8; j++ {
+			ip[i+j] = byte(v >> (8 * j))
+		}
+	}
+	return netip.AddrFrom16(ip).String()
+}
+
+// FakeLorem gets sentence of n lorem ipsum words
+func FakeLorem(r *rand.Rand, n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = pick(r, fakeLoremWords)
+	}
+	words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+	return strings.Join(words, " ") + "."
+}
+
+// Convert money range to cents, range must contain at least one cent
+func moneyCents(minAmount, maxAmount float64) (int64, int64, error) {
+	if err := validateFloat64Args(minAmount, maxAmount); err != nil {
+		return 0, 0, err
+	}
+	const limit = math.MaxInt64 / 100
+	if minAmount < -limit || maxAmount > limit {
+		return 0, 0, fmt.Errorf("min %v and max %v must be within ±%d", minAmount, maxAmount, int64(limit))
+	}
+	minCents, maxCents := int64(math.Round(minAmount*100)), int64(math.Round(maxAmount*100))
+	if minCents >= maxCents {
+		return 0, 0, fmt.Errorf("min %v and max %v round to the same cent", minAmount, maxAmount)
+	}
+	// Span of range in cents must fit int64 to take random value from it
+	if minCents < 0 && maxCents > math.MaxInt64+minCents {
+		return 0, 0, fmt.Errorf("range from min %v to max %v is too wide", minAmount, maxAmount)
+	}
+	return minCents, maxCents, nil
+}
+
+// FakeMoney gets decimal amount in range [minCents, maxCents) as text, so it's bound to numeric column without float rounding
+func FakeMoney(r *rand.Rand, minCents, maxCents int64) string {
+	cents := minCents + r.Int64N(maxCents-minCents)
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return sign + strconv.FormatInt(cents/100, 10) + "." + fmt.Sprintf("%02d", cents%100)
+}
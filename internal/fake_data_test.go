@@ -0,0 +1,141 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGenerators(t *testing.T) {
+	generators, err := GetGenerators("firstName, lastName, email, phone, city, countryCode, lorem 3, lorem 2 5, ipv4, ipv6, dateInRange 2024-02-27 2024-03-02, money 9.99 10.01", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, generators, 12)
+
+	gc := NewGenContext(1, 0, 1)
+	for i := 0; i < 200; i++ {
+		args, err := getArgs(gc, generators)
+		require.NoError(t, err)
+
+		assert.Contains(t, fakeFirstNames, args[0])
+		assert.Contains(t, fakeLastNames, args[1])
+		assert.Regexp(t, `^[a-z]+\.[a-z]+\d{1,3}@[a-z.]+$`, args[2])
+		assert.Regexp(t, `^\+1[2-9]\d{9}$`, args[3])
+		assert.Contains(t, fakeCities, args[4])
+		assert.Contains(t, fakeCountryCodes, args[5])
+		assert.Regexp(t, `^[A-Z][a-z]*( [a-z]+){2}\.$`, args[6])
+		assert.Regexp(t, `^[A-Z][a-z]*( [a-z]+){1,4}\.$`, args[7])
+
+		ip4, err := netip.ParseAddr(args[8].(string))
+		require.NoError(t, err)
+		assert.True(t, ip4.Is4())
+		ip6, err := netip.ParseAddr(args[9].(string))
+		require.NoError(t, err)
+		assert.True(t, ip6.Is6())
+
+		assert.Contains(t, []any{"2024-02-27", "2024-02-28", "2024-02-29", "2024-03-01", "2024-03-02"}, args[10])
+		assert.Contains(t, []any{"9.99", "10.00"}, args[11])
+	}
+
+	errorTests := []struct {
+		args     string
+		errorMsg string
+	}{
+		{"firstName 1", "does not support args"},
+		{"lorem", "requires 1 or 2 arguments"},
+		{"lorem 0", "number of words must be positive"},
+		{"lorem 5 2", "min 5 cannot exceed max 2"},
+		{"dateInRange 2024-01-01", "requires exactly 2 arguments"},
+		{"dateInRange 2024-01-01 tomorrow", "argument parsing error"},
+		{"dateInRange 2024-01-02 2024-01-01", "must be before"},
+		{"money 10 1", "must be less than"},
+		{"money 1.001 1.004", "round to the same cent"},
+		{"money 0 1e20", "must be within"},
+		{"money -1e20 0", "must be within"},
+		{"money -90000000000000000 90000000000000000", "is too wide"},
+	}
+	for _, tt := range errorTests {
+		_, err := GetGenerators(tt.args, nil, nil)
+		assert.ErrorContains(t, err, tt.errorMsg, tt.args)
+	}
+}
+
+func TestFakeMoney(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	pattern := regexp.MustCompile(`^-?\d+\.\d{2}$`)
+	for i := 0; i < 1000; i++ {
+		amount := FakeMoney(r, -150, 150)
+		require.Regexp(t, pattern, amount)
+		v, err := strconv.ParseFloat(amount, 64)
+		require.NoError(t, err)
+		assert.True(t, v >= -1.5 && v < 1.5, amount)
+	}
+	assert.Equal(t, "-0.05", FakeMoney(r, -5, -4))
+}
+
+func TestJSONDocGenerator(t *testing.T) {
+	generators, err := GetGenerators(`jsonDoc name:firstName age:randIntRange:18:90 active:randBool tags:lorem:2`, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, generators, 1)
+
+	gc := NewGenContext(1, 0, 1)
+	args, err := getArgs(gc, generators)
+	require.NoError(t, err)
+	doc := args[0].(string)
+	assert.True(t, strings.HasPrefix(doc, `{"name":`), "fields are kept in configured order: %s", doc)
+
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal([]byte(doc), &obj))
+	assert.Contains(t, fakeFirstNames, obj["name"])
+	assert.GreaterOrEqual(t, obj["age"], 18.0)
+	assert.Less(t, obj["age"], 90.0)
+	assert.IsType(t, true, obj["active"])
+	assert.IsType(t, "", obj["tags"])
+
+	errorTests := []struct {
+		args     string
+		errorMsg string
+	}{
+		{"jsonDoc", "requires at least one field"},
+		{"jsonDoc name", "must be in name:function format"},
+		{"jsonDoc doc:jsonDoc", "cannot be nested jsonDoc"},
+		{"jsonDoc age:randIntRange:1", `field "age"`},
+		{"jsonDoc name:unknown", "unknown function: unknown"},
+	}
+	for _, tt := range errorTests {
+		_, err := GetGenerators(tt.args, nil, nil)
+		assert.ErrorContains(t, err, tt.errorMsg, tt.args)
+	}
+}
+
+func TestFakeGenerators_Seed(t *testing.T) {
+	generators, err := GetGenerators("email, ipv6, dateInRange 2000-01-01 2030-01-01, money 0 1000, jsonDoc city:city code:countryCode", nil, nil)
+	require.NoError(t, err)
+
+	stream := func(seed uint64) [][]any {
+		gc := NewGenContext(1, 0, 1)
+		gc.Seed(seed, 0)
+		values := make([][]any, 0, 5)
+		for i := 0; i < 5; i++ {
+			args, err := getArgs(gc, generators)
+			require.NoError(t, err)
+			values = append(values, args)
+		}
+		return values
+	}
+	assert.Equal(t, stream(42), stream(42))
+	assert.NotEqual(t, stream(42), stream(43))
+}
@@ -0,0 +1,254 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	feederFormatCSV   = "csv"
+	feederFormatJSONL = "jsonl"
+
+	feederModeSequential = "sequential"
+	feederModeRandom     = "random"
+	feederModeUnique     = "unique"
+
+	feederScopeShared = "shared"
+	feederScopeThread = "thread"
+
+	feederOnExhaustedStop    = "stop"
+	feederOnExhaustedRecycle = "recycle"
+
+	maxFeederLineSize = 16 * 1024 * 1024
+)
+
+// ErrFeederExhausted is returned instead of query result when unique feeder has no unused rows,
+// thread which gets it stops
+var ErrFeederExhausted = errors.New("feeder rows are exhausted")
+
+// Feeder holds rows of CSV or JSONL file which are bound to query placeholders by column name
+type Feeder struct {
+	name    string
+	mode    string
+	scope   string
+	recycle bool
+	columns map[string]int
+	rows    [][]any
+	cursor  atomic.Int64 // Next row of shared scope
+}
+
+// LoadFeeders reads files of all feeders, feeders are returned by name
+func LoadFeeders(cfgs []*FeederConfig) (map[string]*Feeder, error) {
+	feeders := make(map[string]*Feeder, len(cfgs))
+	for _, cfg := range cfgs {
+		f, err := NewFeeder(cfg)
+		if err != nil {
+			return nil, err
+		}
+		feeders[cfg.Name] = f
+	}
+	return feeders, nil
+}
+
+func NewFeeder(cfg *FeederConfig) (*Feeder, error) {
+	f := &Feeder{
+		name:    cfg.Name,
+		mode:    cfg.Mode,
+		scope:   cfg.Scope,
+		recycle: cfg.OnExhausted == feederOnExhaustedRecycle,
+		columns: make(map[string]int),
+	}
+	if f.mode == "" {
+		f.mode = feederModeSequential
+	}
+	if f.scope == "" {
+		f.scope = feederScopeShared
+	}
+
+	// #nosec G304 -- path to feeder file is provided by user on purpose
+	file, err := os.Open(filepath.Clean(cfg.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feeder %s: %w", cfg.Name, err)
+	}
+	defer file.Close()
+
+	if feederFormat(cfg) == feederFormatJSONL {
+		err = f.readJSONL(file)
+	} else {
+		err = f.readCSV(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feeder %s: %w", cfg.Name, err)
+	}
+	if len(f.rows) == 0 {
+		return nil, fmt.Errorf("feeder %s has no rows", cfg.Name)
+	}
+	return f, nil
+}
+
+// Get format from config or from file extension
+func feederFormat(cfg *FeederConfig) string {
+	if cfg.Format != "" {
+		return cfg.Format
+	}
+	switch strings.ToLower(filepath.Ext(cfg.Path)) {
+	case ".csv":
+		return feederFormatCSV
+	case ".jsonl", ".ndjson":
+		return feederFormatJSONL
+	}
+	return ""
+}
+
+// Read CSV with header, header names columns
+func (f *Feeder) readCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("header is missing")
+		}
+		return err
+	}
+	for idx, name := range header {
+		f.columns[strings.TrimSpace(name)] = idx
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row := make([]any, len(record))
+		for idx, v := range record {
+			row[idx] = v
+		}
+		f.rows = append(f.rows, row)
+	}
+}
+
+// Read one JSON object per line, keys name columns.
+// Numbers keep their type, nested objects and arrays are bound as JSON text.
+func (f *Feeder) readJSONL(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxFeederLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var obj map[string]any
+		if err := decoder.Decode(&obj); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		// New keys get columns in sorted order, so column indexes don't depend on map order
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, ok := f.columns[key]; !ok {
+				f.columns[key] = len(f.columns)
+			}
+		}
+
+		row := make([]any, len(f.columns))
+		for key, v := range obj {
+			value, err := jsonArg(v)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			row[f.columns[key]] = value
+		}
+		f.rows = append(f.rows, row)
+	}
+	return scanner.Err()
+}
+
+// Convert decoded JSON value to query argument
+func jsonArg(v any) (any, error) {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i, nil
+		}
+		return value.Float64()
+	case map[string]any, []any:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return v, nil
+}
+
+// Column gets generator which returns value of column from row taken by thread in current iteration
+func (f *Feeder) Column(name string) (GeneratorFunc, error) {
+	idx, ok := f.columns[name]
+	if !ok {
+		return nil, fmt.Errorf("feeder %s has no column %s", f.name, name)
+	}
+	return func(gc *GenContext) any {
+		row := gc.row(f)
+		if idx >= len(row) {
+			return nil
+		}
+		return row[idx]
+	}, nil
+}
+
+// Take next row for thread, with thread scope thread reads only every n-th row starting from its partition
+func (f *Feeder) next(gc *GenContext) ([]any, error) {
+	start, step := 0, 1
+	if f.scope == feederScopeThread && gc.partitions > 1 {
+		start, step = gc.partition, gc.partitions
+	}
+	size := int64(0)
+	if start < len(f.rows) {
+		size = int64((len(f.rows) - start + step - 1) / step)
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("%w: feeder %s has no rows for thread %d", ErrFeederExhausted, f.name, gc.ThreadId)
+	}
+
+	var n int64
+	switch {
+	case f.mode == feederModeRandom:
+		n = gc.Rand.Int64N(size)
+	case f.scope == feederScopeThread:
+		n = gc.cursors[f]
+		gc.cursors[f]++
+	default:
+		n = f.cursor.Add(1) - 1
+	}
+	if n >= size && f.mode == feederModeUnique && !f.recycle {
+		return nil, fmt.Errorf("%w: feeder %s", ErrFeederExhausted, f.name)
+	}
+	return f.rows[start+int(n%size)*step], nil
+}
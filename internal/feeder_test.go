@@ -0,0 +1,170 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFeederFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+// Take values of column from n iterations of thread
+func feedColumn(t *testing.T, f *Feeder, column string, gc *GenContext, n int) []any {
+	t.Helper()
+	gen, err := f.Column(column)
+	require.NoError(t, err)
+	values := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		gc.Reset()
+		args, err := getArgs(gc, []GeneratorFunc{gen})
+		require.NoError(t, err)
+		values = append(values, args[0])
+	}
+	return values
+}
+
+func TestNewFeeder(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		path := writeFeederFile(t, "customers.csv", "id,region\n1,eu\n2,us\n")
+		f, err := NewFeeder(&FeederConfig{Name: "customers", Path: path})
+		require.NoError(t, err)
+
+		assert.Len(t, f.rows, 2)
+		assert.Equal(t, []any{"1", "eu"}, f.rows[0])
+		assert.Equal(t, map[string]int{"id": 0, "region": 1}, f.columns)
+	})
+	t.Run("jsonl", func(t *testing.T) {
+		path := writeFeederFile(t, "terms.jsonl", `{"term":"red shoes","limit":10,"score":0.5,"filters":{"size":42}}`+"\n\n"+`{"term":"hat","extra":null}`+"\n")
+		f, err := NewFeeder(&FeederConfig{Name: "terms", Path: path})
+		require.NoError(t, err)
+
+		require.Len(t, f.rows, 2)
+		row := f.rows[0]
+		assert.Equal(t, "red shoes", row[f.columns["term"]])
+		assert.Equal(t, int64(10), row[f.columns["limit"]])
+		assert.Equal(t, 0.5, row[f.columns["score"]])
+		assert.Equal(t, `{"size":42}`, row[f.columns["filters"]])
+
+		gc := NewGenContext(1, 0, 1)
+		assert.Equal(t, []any{int64(10), nil}, feedColumn(t, f, "limit", gc, 2))
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := NewFeeder(&FeederConfig{Name: "missing", Path: filepath.Join(t.TempDir(), "missing.csv")})
+		assert.ErrorContains(t, err, "failed to open feeder missing")
+
+		_, err = NewFeeder(&FeederConfig{Name: "empty", Path: writeFeederFile(t, "empty.csv", "id\n")})
+		assert.ErrorContains(t, err, "feeder empty has no rows")
+
+		_, err = NewFeeder(&FeederConfig{Name: "broken", Path: writeFeederFile(t, "broken.jsonl", "{\"id\":1}\n{id}\n")})
+		assert.ErrorContains(t, err, "line 2")
+	})
+}
+
+func TestFeeder_Modes(t *testing.T) {
+	path := writeFeederFile(t, "ids.csv", "id\n1\n2\n3\n")
+
+	t.Run("sequential wraps around", func(t *testing.T) {
+		f, err := NewFeeder(&FeederConfig{Name: "ids", Path: path})
+		require.NoError(t, err)
+		assert.Equal(t, []any{"1", "2", "3", "1"}, feedColumn(t, f, "id", NewGenContext(1, 0, 1), 4))
+	})
+	t.Run("cursor is shared by threads", func(t *testing.T) {
+		f, err := NewFeeder(&FeederConfig{Name: "ids", Path: path, Mode: feederModeUnique})
+		require.NoError(t, err)
+		assert.Equal(t, []any{"1", "2"}, feedColumn(t, f, "id", NewGenContext(1, 0, 2), 2))
+		assert.Equal(t, []any{"3"}, feedColumn(t, f, "id", NewGenContext(2, 1, 2), 1))
+	})
+	t.Run("random", func(t *testing.T) {
+		f, err := NewFeeder(&FeederConfig{Name: "ids", Path: path, Mode: feederModeRandom})
+		require.NoError(t, err)
+		for _, v := range feedColumn(t, f, "id", NewGenContext(1, 0, 1), 20) {
+			assert.Contains(t, []any{"1", "2", "3"}, v)
+		}
+	})
+	t.Run("unique stops", func(t *testing.T) {
+		f, err := NewFeeder(&FeederConfig{Name: "ids", Path: path, Mode: feederModeUnique})
+		require.NoError(t, err)
+		gen, err := f.Column("id")
+		require.NoError(t, err)
+
+		gc := NewGenContext(1, 0, 1)
+		for i := 0; i < 3; i++ {
+			gc.Reset()
+			_, err := getArgs(gc, []GeneratorFunc{gen})
+			require.NoError(t, err)
+		}
+		gc.Reset()
+		_, err = getArgs(gc, []GeneratorFunc{gen})
+		assert.ErrorIs(t, err, ErrFeederExhausted)
+	})
+	t.Run("unique recycles", func(t *testing.T) {
+		f, err := NewFeeder(&FeederConfig{Name: "ids", Path: path, Mode: feederModeUnique, OnExhausted: feederOnExhaustedRecycle})
+		require.NoError(t, err)
+		assert.Equal(t, []any{"1", "2", "3", "1"}, feedColumn(t, f, "id", NewGenContext(1, 0, 1), 4))
+	})
+	t.Run("thread scope partitions rows", func(t *testing.T) {
+		f, err := NewFeeder(&FeederConfig{Name: "ids", Path: path, Scope: feederScopeThread})
+		require.NoError(t, err)
+		assert.Equal(t, []any{"1", "3", "1"}, feedColumn(t, f, "id", NewGenContext(1, 0, 2), 3))
+		assert.Equal(t, []any{"2", "2"}, feedColumn(t, f, "id", NewGenContext(2, 1, 2), 2))
+
+		gc := NewGenContext(5, 4, 5)
+		gen, err := f.Column("id")
+		require.NoError(t, err)
+		_, err = getArgs(gc, []GeneratorFunc{gen})
+		assert.ErrorIs(t, err, ErrFeederExhausted, "thread without rows")
+	})
+}
+
+func TestGetGenerators_Feed(t *testing.T) {
+	path := writeFeederFile(t, "customers.csv", "id,region\n1,eu\n2,us\n")
+	feeders, err := LoadFeeders([]*FeederConfig{{Name: "customers", Path: path}})
+	require.NoError(t, err)
+
+	generators, err := GetGenerators("feed customers id, randBool, feed customers region", feeders, nil)
+	require.NoError(t, err)
+	require.Len(t, generators, 3)
+
+	// Columns of one iteration come from the same row
+	gc := NewGenContext(1, 0, 1)
+	args, err := getArgs(gc, generators)
+	require.NoError(t, err)
+	assert.Equal(t, "1", args[0])
+	assert.Equal(t, "eu", args[2])
+	args, err = getArgs(gc, generators)
+	require.NoError(t, err)
+	assert.Equal(t, "1", args[0], "row is kept until the next iteration")
+
+	gc.Reset()
+	args, err = getArgs(gc, generators)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"2", "us"}, []any{args[0], args[2]})
+
+	tests := []struct {
+		args     string
+		errorMsg string
+	}{
+		{"feed customers", "feed requires feeder name and column"},
+		{"feed orders id", "unknown feeder: orders"},
+		{"feed customers email", "feeder customers has no column email"},
+	}
+	for _, tt := range tests {
+		_, err := GetGenerators(tt.args, feeders, nil)
+		assert.ErrorContains(t, err, tt.errorMsg, tt.args)
+	}
+}
@@ -8,12 +8,17 @@ Licensed under the MIT License.
 package internal
 
 import (
+	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,12 +26,164 @@ import (
 
 const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
-type GeneratorFunc func() any
+// GeneratorFunc gets value of query argument, gc is state of the thread which executes query
+type GeneratorFunc func(gc *GenContext) any
 
-func GetGenerators(args string) ([]GeneratorFunc, error) {
+// GenContext holds state of the thread used by generators, it's passed to them through context of execution
+type GenContext struct {
+	ThreadId  int
+	Iteration int64      // Number of current iteration of the thread, starting from 0
+	Rand      *rand.Rand // Source of all random values of the thread
+
+	partition  int // Index of thread in scenario
+	partitions int // Number of threads in scenario
+
+	rows    map[*Feeder][]any // Rows taken by feeders in current iteration
+	cursors map[*Feeder]int64 // Next rows of feeders with thread scope
+	err     error             // First failure to take row in current iteration
+
+	iterations int64                    // Iterations started by the thread
+	counters   map[*threadCounter]int64 // Values taken from counters of the thread
+
+	zipfs map[zipfKey]*rand.Zipf // Zipf generators are bound to random source of the thread
+}
+
+// threadCounter is sequence of values counted separately by each thread
+type threadCounter struct {
+	start int64
+	step  int64
+}
+
+type zipfKey struct {
+	s    float64
+	imax uint64
+}
+
+type genCtxKey struct{}
+
+func NewGenContext(threadId, partition, partitions int) *GenContext {
+	return &GenContext{
+		ThreadId: threadId,
+		// #nosec G404 -- Non-security random generation for test data
+		Rand:       rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		partition:  partition,
+		partitions: partitions,
+		rows:       make(map[*Feeder][]any),
+		cursors:    make(map[*Feeder]int64),
+		zipfs:      make(map[zipfKey]*rand.Zipf),
+		counters:   make(map[*threadCounter]int64),
+	}
+}
+
+// Seed makes random values of the thread reproducible: the same seed, scenario index and
+// thread index always give the same values. Threads get generators with different seeds,
+// PCG gives no guarantee their sequences never overlap, though it's unlikely for test data.
+func (gc *GenContext) Seed(seed uint64, scenario int) {
+	// #nosec G404 -- Non-security random generation for test data
+	gc.Rand = rand.New(rand.NewPCG(seed, uint64(scenario)<<32|uint64(gc.partition)))
+	clear(gc.zipfs)
+}
+
+// Get Zipf generator of the thread with exponent s for values in [0, imax]
+func (gc *GenContext) zipf(s float64, imax uint64) *rand.Zipf {
+	key := zipfKey{s: s, imax: imax}
+	z, ok := gc.zipfs[key]
+	if !ok {
+		z = rand.NewZipf(gc.Rand, s, 1, imax)
+		gc.zipfs[key] = z
+	}
+	return z
+}
+
+// ContextWithGen returns context which makes generators of queries use state of the thread
+func ContextWithGen(ctx context.Context, gc *GenContext) context.Context {
+	return context.WithValue(ctx, genCtxKey{}, gc)
+}
+
+// Get state of the thread from context, queries executed outside of thread get fresh state on each call
+func genFromContext(ctx context.Context) *GenContext {
+	if gc, ok := ctx.Value(genCtxKey{}).(*GenContext); ok {
+		return gc
+	}
+	return NewGenContext(0, 0, 1)
+}
+
+// Reset starts next iteration of the thread, feeders take new rows
+func (gc *GenContext) Reset() {
+	clear(gc.rows)
+	gc.err = nil
+	gc.Iteration = gc.iterations
+	gc.iterations++
+}
+
+// Get next value of counter of the thread
+func (gc *GenContext) count(c *threadCounter) int64 {
+	n := gc.counters[c]
+	gc.counters[c]++
+	return c.start + n*c.step
+}
+
+// Get row taken by feeder in current iteration, every statement of iteration gets the same row
+func (gc *GenContext) row(f *Feeder) []any {
+	if row, ok := gc.rows[f]; ok {
+		return row
+	}
+	row, err := f.next(gc)
+	if err != nil && gc.err == nil {
+		gc.err = err
+	}
+	gc.rows[f] = row
+	return row
+}
+
+// Sequences holds named seq and threadSeq counters of scenario, arguments with the same name
+// share one counter in all statements of scenario. Unnamed arguments have their own counters.
+type Sequences struct {
+	mu    sync.Mutex
+	named map[string]*sequence
+}
+
+// sequence is counter of seq shared by threads, threadSeq counts separately in each thread by threadCounter
+type sequence struct {
+	threadCounter
+	counter atomic.Int64
+}
+
+var seqNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func NewSequences() *Sequences {
+	return &Sequences{named: make(map[string]*sequence)}
+}
+
+// Get sequence of function, named sequence is created on first use and must keep its start and step
+func (s *Sequences) get(funcName, name string, start, step int64) (*sequence, error) {
+	if name == "" {
+		return &sequence{threadCounter: threadCounter{start: start, step: step}}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := funcName + " " + name
+	seq, ok := s.named[key]
+	if !ok {
+		seq = &sequence{threadCounter: threadCounter{start: start, step: step}}
+		s.named[key] = seq
+	}
+	if seq.start != start || seq.step != step {
+		return nil, fmt.Errorf("%s() %q is already used with start %d and step %d", funcName, name, seq.start, seq.step)
+	}
+	return seq, nil
+}
+
+// GetGenerators parses comma-separated list of argument functions,
+// feeders are referenced by name as "feed <name> <column>".
+// Named seq counters are taken from seqs, if seqs is nil they are shared only within args.
+func GetGenerators(args string, feeders map[string]*Feeder, seqs *Sequences) ([]GeneratorFunc, error) {
 	if args == "" {
 		return nil, errors.New("args is empty")
 	}
+	if seqs == nil {
+		seqs = NewSequences()
+	}
 	argsSplit := strings.Split(args, ",")
 
 	generators := make([]GeneratorFunc, 0, len(argsSplit))
@@ -47,7 +204,7 @@ func GetGenerators(args string) ([]GeneratorFunc, error) {
 			if len(funcSignature) > 1 {
 				return nil, fmt.Errorf("invalid function signature, randBool() does not support args: %v", funcSignature)
 			}
-			generators = append(generators, func() any { return RandBool() })
+			generators = append(generators, func(gc *GenContext) any { return RandBool(gc.Rand) })
 
 		case "randIntRange":
 			if len(funcSignature) != 3 {
@@ -61,7 +218,7 @@ func GetGenerators(args string) ([]GeneratorFunc, error) {
 			if err := validateIntArgs(arg1, arg2); err != nil {
 				return nil, fmt.Errorf("randIntRange() validation error: %w", err)
 			}
-			generators = append(generators, func() any { return RandIntRange(arg1, arg2) })
+			generators = append(generators, func(gc *GenContext) any { return RandIntRange(gc.Rand, arg1, arg2) })
 
 		case "randFloat64InRange":
 			if len(funcSignature) != 3 {
@@ -75,15 +232,15 @@ func GetGenerators(args string) ([]GeneratorFunc, error) {
 			if err := validateFloat64Args(arg1, arg2); err != nil {
 				return nil, fmt.Errorf("randFloat64InRange() validation error: %w", err)
 			}
-			generators = append(generators, func() any {
-				return RandFloat64InRange(arg1, arg2)
+			generators = append(generators, func(gc *GenContext) any {
+				return RandFloat64InRange(gc.Rand, arg1, arg2)
 			})
 
 		case "randUUID":
 			if len(funcSignature) > 1 {
 				return nil, fmt.Errorf("invalid function signature, randUUID() does not support args: %v", funcSignature)
 			}
-			generators = append(generators, func() any { return RandUUID() })
+			generators = append(generators, func(gc *GenContext) any { return RandUUID(gc.Rand) })
 
 		case "randStringInRange", "randStrRange": // Support both variants
 			if len(funcSignature) != 3 {
@@ -97,16 +254,166 @@ func GetGenerators(args string) ([]GeneratorFunc, error) {
 			if err := validateIntArgs(arg1, arg2); err != nil {
 				return nil, fmt.Errorf("randStringInRange() validation error: %w", err)
 			}
-			generators = append(generators, func() any { return RandStringInRange(arg1, arg2) })
+			generators = append(generators, func(gc *GenContext) any { return RandStringInRange(gc.Rand, arg1, arg2) })
 
 		case "getTimestampNow":
 			if len(funcSignature) > 1 {
 				return nil, fmt.Errorf("invalid function signature, getTimestampNow() does not support args: %v", funcSignature)
 			}
-			generators = append(generators, func() any { return GetTimestampNow() })
+			generators = append(generators, func(*GenContext) any { return GetTimestampNow() })
+
+		case "zipfInt":
+			if len(funcSignature) != 4 {
+				return nil, fmt.Errorf("zipfInt() requires exactly 3 arguments, got %d: %v", len(funcSignature)-1, funcSignature)
+			}
+			parsedArgs, err := parseInt(funcSignature[1:3]...)
+			if err != nil {
+				return nil, fmt.Errorf("zipfInt() argument parsing error: %w", err)
+			}
+			s, err := parseFloat64(funcSignature[3])
+			if err != nil {
+				return nil, fmt.Errorf("zipfInt() argument parsing error: %w", err)
+			}
+			arg1, arg2, exp := parsedArgs[0], parsedArgs[1], s[0]
+			if err := validateIntArgs(arg1, arg2); err != nil {
+				return nil, fmt.Errorf("zipfInt() validation error: %w", err)
+			}
+			if !(exp > 1) || math.IsInf(exp, 0) {
+				return nil, fmt.Errorf("zipfInt() validation error: exponent %v must be greater than 1", exp)
+			}
+			generators = append(generators, func(gc *GenContext) any {
+				return arg1 + int(gc.zipf(exp, uint64(arg2-arg1-1)).Uint64())
+			})
+
+		case "normalInt":
+			if len(funcSignature) != 3 {
+				return nil, fmt.Errorf("normalInt() requires exactly 2 arguments, got %d: %v", len(funcSignature)-1, funcSignature)
+			}
+			parsedArgs, err := parseFloat64(funcSignature[1:]...)
+			if err != nil {
+				return nil, fmt.Errorf("normalInt() argument parsing error: %w", err)
+			}
+			mean, stdDev := parsedArgs[0], parsedArgs[1]
+			if math.IsInf(mean, 0) || math.IsNaN(mean) || !(stdDev > 0) || math.IsInf(stdDev, 0) {
+				return nil, fmt.Errorf("normalInt() validation error: mean %v must be a number and stddev %v must be positive", mean, stdDev)
+			}
+			generators = append(generators, func(gc *GenContext) any { return NormalInt(gc.Rand, mean, stdDev) })
+
+		case "exponentialInt":
+			if len(funcSignature) != 4 {
+				return nil, fmt.Errorf("exponentialInt() requires exactly 3 arguments, got %d: %v", len(funcSignature)-1, funcSignature)
+			}
+			parsedArgs, err := parseInt(funcSignature[1:3]...)
+			if err != nil {
+				return nil, fmt.Errorf("exponentialInt() argument parsing error: %w", err)
+			}
+			parsedMean, err := parseFloat64(funcSignature[3])
+			if err != nil {
+				return nil, fmt.Errorf("exponentialInt() argument parsing error: %w", err)
+			}
+			arg1, arg2, mean := parsedArgs[0], parsedArgs[1], parsedMean[0]
+			if err := validateIntArgs(arg1, arg2); err != nil {
+				return nil, fmt.Errorf("exponentialInt() validation error: %w", err)
+			}
+			// Large mean would make most values fall out of range and be drawn again
+			if !(mean > 0) || mean > float64(arg2-arg1) {
+				return nil, fmt.Errorf("exponentialInt() validation error: mean %v must be positive and not greater than range size %d", mean, arg2-arg1)
+			}
+			generators = append(generators, func(gc *GenContext) any { return ExponentialInt(gc.Rand, arg1, arg2, mean) })
+
+		case "hotspotInt":
+			if len(funcSignature) != 5 {
+				return nil, fmt.Errorf("hotspotInt() requires exactly 4 arguments, got %d: %v", len(funcSignature)-1, funcSignature)
+			}
+			parsedArgs, err := parseInt(funcSignature[1:3]...)
+			if err != nil {
+				return nil, fmt.Errorf("hotspotInt() argument parsing error: %w", err)
+			}
+			fractions, err := parseFloat64(funcSignature[3:]...)
+			if err != nil {
+				return nil, fmt.Errorf("hotspotInt() argument parsing error: %w", err)
+			}
+			arg1, arg2, hotFraction, hotProbability := parsedArgs[0], parsedArgs[1], fractions[0], fractions[1]
+			if err := validateIntArgs(arg1, arg2); err != nil {
+				return nil, fmt.Errorf("hotspotInt() validation error: %w", err)
+			}
+			if !(hotFraction > 0 && hotFraction < 1) || !(hotProbability >= 0 && hotProbability <= 1) {
+				return nil, fmt.Errorf("hotspotInt() validation error: hot fraction %v must be between 0 and 1 exclusive and hot probability %v between 0 and 1", hotFraction, hotProbability)
+			}
+			generators = append(generators, func(gc *GenContext) any {
+				return HotspotInt(gc.Rand, arg1, arg2, hotFraction, hotProbability)
+			})
+
+		case "seq", "threadSeq":
+			// Optional name comes first, e.g. "seq orders 1000 1"
+			name, params := "", funcSignature[1:]
+			if len(params) == 1 || len(params) == 3 {
+				name, params = params[0], params[1:]
+				if !seqNameRe.MatchString(name) {
+					return nil, fmt.Errorf("%s() validation error: name %q must start with letter and contain only letters, digits and _", funcName, name)
+				}
+			}
+			start, step := int64(1), int64(1)
+			switch len(params) {
+			case 0:
+			case 2:
+				parsedArgs, err := parseInt(params...)
+				if err != nil {
+					return nil, fmt.Errorf("%s() argument parsing error: %w", funcName, err)
+				}
+				start, step = int64(parsedArgs[0]), int64(parsedArgs[1])
+			default:
+				return nil, fmt.Errorf("%s() requires optional name and no arguments or exactly 2 arguments, got %d: %v", funcName, len(funcSignature)-1, funcSignature)
+			}
+			if step == 0 {
+				return nil, fmt.Errorf("%s() validation error: step cannot be zero", funcName)
+			}
+			seq, err := seqs.get(funcName, name, start, step)
+			if err != nil {
+				return nil, err
+			}
+			if funcName == "threadSeq" {
+				generators = append(generators, func(gc *GenContext) any { return gc.count(&seq.threadCounter) })
+				break
+			}
+			// Counter is shared by all threads of scenario
+			generators = append(generators, func(*GenContext) any { return seq.start + (seq.counter.Add(1)-1)*seq.step })
+
+		case "threadId":
+			if len(funcSignature) > 1 {
+				return nil, fmt.Errorf("invalid function signature, threadId() does not support args: %v", funcSignature)
+			}
+			generators = append(generators, func(gc *GenContext) any { return gc.ThreadId })
+
+		case "iteration":
+			if len(funcSignature) > 1 {
+				return nil, fmt.Errorf("invalid function signature, iteration() does not support args: %v", funcSignature)
+			}
+			generators = append(generators, func(gc *GenContext) any { return gc.Iteration })
+
+		case "feed":
+			if len(funcSignature) != 3 {
+				return nil, fmt.Errorf("feed requires feeder name and column, got %d arguments: %v", len(funcSignature)-1, funcSignature)
+			}
+			feeder, ok := feeders[funcSignature[1]]
+			if !ok {
+				return nil, fmt.Errorf("unknown feeder: %s", funcSignature[1])
+			}
+			column, err := feeder.Column(funcSignature[2])
+			if err != nil {
+				return nil, err
+			}
+			generators = append(generators, column)
 
 		default:
-			return nil, fmt.Errorf("unknown function: %s", funcName)
+			gen, ok, err := getFakeGenerator(funcSignature, feeders, seqs)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("unknown function: %s", funcName)
+			}
+			generators = append(generators, gen)
 		}
 	}
 
@@ -141,34 +448,35 @@ func parseFloat64(args ...string) ([]float64, error) {
 	return result, nil
 }
 
-func RandBool() bool {
-	return rand.IntN(2) == 1 // #nosec G404 -- Non-security random generation for test data
+func RandBool(r *rand.Rand) bool {
+	return r.IntN(2) == 1
 }
 
-func RandIntRange(min, max int) int {
+func RandIntRange(r *rand.Rand, min, max int) int {
 	if min >= max {
 		return min // Fallback for invalid range
 	}
-	return rand.IntN(max-min) + min // #nosec G404 -- Non-security random generation for test data
+	return r.IntN(max-min) + min
 }
 
-func RandFloat64InRange(min, max float64) float64 {
+func RandFloat64InRange(r *rand.Rand, min, max float64) float64 {
 	if min >= max {
 		return min // Fallback for invalid range
 	}
-	return rand.Float64()*(max-min) + min // #nosec G404 -- Non-security random generation for test data
+	return r.Float64()*(max-min) + min
 }
 
-func RandUUID() string {
-	u, err := uuid.NewRandom()
-	if err != nil {
-		// Return a fallback UUID instead of empty string
-		return "00000000-0000-0000-0000-000000000000"
-	}
+// RandUUID gets version 4 UUID made of random source bits, so seeded source gives the same UUIDs
+func RandUUID(r *rand.Rand) string {
+	var u uuid.UUID
+	binary.LittleEndian.PutUint64(u[:8], r.Uint64())
+	binary.LittleEndian.PutUint64(u[8:], r.Uint64())
+	u[6] = (u[6] & 0x0f) | 0x40 // Version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // Variant RFC 4122
 	return u.String()
 }
 
-func RandStringInRange(min, max int) string {
+func RandStringInRange(r *rand.Rand, min, max int) string {
 	if min >= max {
 		return "" // Fallback for invalid range
 	}
@@ -176,18 +484,53 @@ func RandStringInRange(min, max int) string {
 		min = 0
 	}
 
-	n := rand.IntN(max-min+1) + min // #nosec G404 -- Non-security random generation for test data
+	n := r.IntN(max-min+1) + min
 	if n == 0 {
 		return ""
 	}
 
 	b := make([]byte, n)
 	for i := 0; i < len(b); i++ {
-		b[i] = letters[rand.IntN(len(letters))] // #nosec G404 -- Non-security random generation for test data
+		b[i] = letters[r.IntN(len(letters))]
 	}
 	return string(b)
 }
 
+// NormalInt gets integer from normal distribution, values are not bounded
+func NormalInt(r *rand.Rand, mean, stdDev float64) int {
+	return int(math.Round(r.NormFloat64()*stdDev + mean))
+}
+
+// ExponentialInt gets integer in range [min, max) from exponential distribution shifted by min,
+// values out of range are drawn again
+func ExponentialInt(r *rand.Rand, min, max int, mean float64) int {
+	if min >= max {
+		return min // Fallback for invalid range
+	}
+	for {
+		v := min + int(r.ExpFloat64()*mean)
+		if v < max {
+			return v
+		}
+	}
+}
+
+// HotspotInt gets integer in range [min, max), with hotProbability it's taken from
+// the first hotFraction of range, otherwise from the rest of range
+func HotspotInt(r *rand.Rand, min, max int, hotFraction, hotProbability float64) int {
+	if min >= max {
+		return min // Fallback for invalid range
+	}
+	hot := int(float64(max-min) * hotFraction)
+	if hot < 1 {
+		hot = 1
+	}
+	if hot >= max-min || r.Float64() < hotProbability {
+		return min + r.IntN(hot)
+	}
+	return min + hot + r.IntN(max-min-hot)
+}
+
 func GetTimestampNow() string {
 	return time.Now().Format("2006-01-02 15:04:05.999999")
 }
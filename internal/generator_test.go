@@ -9,6 +9,7 @@ package internal
 
 import (
 	"math"
+	"math/rand/v2"
 	"testing"
 	"time"
 
@@ -196,7 +197,7 @@ func TestGetGenerators(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			generators, err := GetGenerators(tt.args)
+			generators, err := GetGenerators(tt.args, nil, nil)
 
 			if tt.expectError {
 				require.Error(t, err)
@@ -209,7 +210,7 @@ func TestGetGenerators(t *testing.T) {
 				assert.Len(t, generators, tt.expectCount)
 				// Test that generators actually work
 				for _, gen := range generators {
-					result := gen()
+					result := gen(NewGenContext(1, 0, 1))
 					assert.NotNil(t, result)
 				}
 			}
@@ -218,13 +219,15 @@ func TestGetGenerators(t *testing.T) {
 }
 
 func TestRandBool(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
 	// Test that it returns both true and false over many iterations
 	trueCount := 0
 	falseCount := 0
 	iterations := 1000
 
 	for i := 0; i < iterations; i++ {
-		result := RandBool()
+		result := RandBool(r)
 		if result {
 			trueCount++
 		} else {
@@ -239,6 +242,8 @@ func TestRandBool(t *testing.T) {
 }
 
 func TestRandIntRange(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
 	tests := []struct {
 		name string
 		min  int
@@ -252,7 +257,7 @@ func TestRandIntRange(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			for i := 0; i < 100; i++ {
-				result := RandIntRange(tt.min, tt.max)
+				result := RandIntRange(r, tt.min, tt.max)
 				assert.GreaterOrEqual(t, result, tt.min)
 				assert.Less(t, result, tt.max)
 			}
@@ -260,15 +265,17 @@ func TestRandIntRange(t *testing.T) {
 	}
 
 	t.Run("invalid range min >= max", func(t *testing.T) {
-		result := RandIntRange(10, 5)
+		result := RandIntRange(r, 10, 5)
 		assert.Equal(t, 10, result) // Should return min as fallback
 
-		result = RandIntRange(5, 5)
+		result = RandIntRange(r, 5, 5)
 		assert.Equal(t, 5, result) // Should return min as fallback
 	})
 }
 
 func TestRandFloat64InRange(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
 	tests := []struct {
 		name string
 		min  float64
@@ -283,7 +290,7 @@ func TestRandFloat64InRange(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			for i := 0; i < 100; i++ {
-				result := RandFloat64InRange(tt.min, tt.max)
+				result := RandFloat64InRange(r, tt.min, tt.max)
 				assert.GreaterOrEqual(t, result, tt.min)
 				assert.Less(t, result, tt.max)
 			}
@@ -291,17 +298,19 @@ func TestRandFloat64InRange(t *testing.T) {
 	}
 
 	t.Run("invalid range min >= max", func(t *testing.T) {
-		result := RandFloat64InRange(10.0, 5.0)
+		result := RandFloat64InRange(r, 10.0, 5.0)
 		assert.Equal(t, 10.0, result) // Should return min as fallback
 
-		result = RandFloat64InRange(5.0, 5.0)
+		result = RandFloat64InRange(r, 5.0, 5.0)
 		assert.Equal(t, 5.0, result) // Should return min as fallback
 	})
 }
 
 func TestRandUUID(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
 	// Test that it generates valid UUID format
-	result := RandUUID()
+	result := RandUUID(r)
 	assert.NotEmpty(t, result)
 
 	// UUID should be 36 characters with hyphens at positions 8, 13, 18, 23
@@ -312,12 +321,14 @@ func TestRandUUID(t *testing.T) {
 	assert.Equal(t, "-", string(result[23]))
 
 	// Test that multiple calls generate different UUIDs
-	uuid1 := RandUUID()
-	uuid2 := RandUUID()
+	uuid1 := RandUUID(r)
+	uuid2 := RandUUID(r)
 	assert.NotEqual(t, uuid1, uuid2)
 }
 
 func TestRandStringInRange(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
 	tests := []struct {
 		name string
 		min  int
@@ -331,7 +342,7 @@ func TestRandStringInRange(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			for i := 0; i < 100; i++ {
-				result := RandStringInRange(tt.min, tt.max)
+				result := RandStringInRange(r, tt.min, tt.max)
 				assert.GreaterOrEqual(t, len(result), tt.min)
 				assert.LessOrEqual(t, len(result), tt.max)
 
@@ -344,21 +355,21 @@ func TestRandStringInRange(t *testing.T) {
 	}
 
 	t.Run("zero length range", func(t *testing.T) {
-		result := RandStringInRange(0, 1)
+		result := RandStringInRange(r, 0, 1)
 		assert.GreaterOrEqual(t, len(result), 0)
 		assert.LessOrEqual(t, len(result), 1)
 	})
 
 	t.Run("invalid range min >= max", func(t *testing.T) {
-		result := RandStringInRange(10, 5)
+		result := RandStringInRange(r, 10, 5)
 		assert.Equal(t, "", result) // Should return empty string as fallback
 
-		result = RandStringInRange(5, 5)
+		result = RandStringInRange(r, 5, 5)
 		assert.Equal(t, "", result) // Should return empty string as fallback
 	})
 
 	t.Run("negative min", func(t *testing.T) {
-		result := RandStringInRange(-5, 10)
+		result := RandStringInRange(r, -5, 10)
 		assert.GreaterOrEqual(t, len(result), 0) // Should clamp min to 0
 		assert.LessOrEqual(t, len(result), 10)
 	})
@@ -646,44 +657,208 @@ func TestValidateFloat64Args(t *testing.T) {
 
 func TestGeneratorFuncIntegration(t *testing.T) {
 	t.Run("integration test", func(t *testing.T) {
-		generators, err := GetGenerators("randBool, randUUID, randIntRange 1 10, randFloat64InRange 1.5 10.5, randStringInRange 5 15, getTimestampNow")
+		generators, err := GetGenerators("randBool, randUUID, randIntRange 1 10, randFloat64InRange 1.5 10.5, randStringInRange 5 15, getTimestampNow", nil, nil)
 		require.NoError(t, err)
 		require.Len(t, generators, 6)
+		gc := NewGenContext(1, 0, 1)
 
 		// Test multiple times to catch intermittent issues
 		for i := 0; i < 10; i++ {
 			// Test randBool generator
-			boolResult := generators[0]()
+			boolResult := generators[0](gc)
 			assert.IsType(t, true, boolResult)
 
 			// Test randUUID generator
-			uuidResult := generators[1]()
+			uuidResult := generators[1](gc)
 			assert.IsType(t, "", uuidResult)
 			assert.Len(t, uuidResult.(string), 36)
 
 			// Test randIntRange generator (check your actual implementation)
-			intResult := generators[2]()
+			intResult := generators[2](gc)
 			assert.IsType(t, 0, intResult)
 			assert.GreaterOrEqual(t, intResult.(int), 1)
 			// Adjust this based on whether your range is [1,10) or [1,10]
 			assert.Less(t, intResult.(int), 11) // Safe for both cases
 
 			// Test randFloat64InRange generator
-			floatResult := generators[3]()
+			floatResult := generators[3](gc)
 			assert.IsType(t, 0.0, floatResult)
 			assert.GreaterOrEqual(t, floatResult.(float64), 1.5)
 			assert.Less(t, floatResult.(float64), 10.5)
 
 			// Test randStringInRange generator
-			stringResult := generators[4]()
+			stringResult := generators[4](gc)
 			assert.IsType(t, "", stringResult)
 			strLen := len(stringResult.(string))
 			assert.True(t, strLen >= 5 && strLen <= 15, "String length %d not in range [5,15]", strLen)
 
 			// Test getTimestampNow generator - be more flexible
-			timestampResult := generators[5]()
+			timestampResult := generators[5](gc)
 			assert.IsType(t, "", timestampResult)
 			assert.NotEmpty(t, timestampResult.(string))
 		}
 	})
 }
+
+func TestGenContext_Seed(t *testing.T) {
+	generators, err := GetGenerators("randIntRange 1 1000000, randUUID, randStringInRange 5 10, randFloat64InRange 0 1", nil, nil)
+	require.NoError(t, err)
+
+	stream := func(seed uint64, scenario, partition int) [][]any {
+		gc := NewGenContext(partition+1, partition, 4)
+		gc.Seed(seed, scenario)
+		values := make([][]any, 0, 5)
+		for i := 0; i < 5; i++ {
+			args, err := getArgs(gc, generators)
+			require.NoError(t, err)
+			values = append(values, args)
+		}
+		return values
+	}
+
+	assert.Equal(t, stream(42, 0, 1), stream(42, 0, 1), "same seed gives the same values")
+	assert.NotEqual(t, stream(42, 0, 1), stream(42, 0, 2), "threads get different streams")
+	assert.NotEqual(t, stream(42, 0, 1), stream(42, 1, 1), "scenarios get different streams")
+	assert.NotEqual(t, stream(42, 0, 1), stream(43, 0, 1))
+}
+
+func TestDistributionGenerators(t *testing.T) {
+	const samples = 20000
+	draw := func(args string) []int {
+		generators, err := GetGenerators(args, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, generators, 1)
+		gc := NewGenContext(1, 0, 1)
+		gc.Seed(1, 0)
+		values := make([]int, samples)
+		for i := range values {
+			values[i] = generators[0](gc).(int)
+		}
+		return values
+	}
+	mean := func(values []int) float64 {
+		sum := 0
+		for _, v := range values {
+			sum += v
+		}
+		return float64(sum) / float64(len(values))
+	}
+
+	t.Run("zipfInt", func(t *testing.T) {
+		counts := make(map[int]int)
+		for _, v := range draw("zipfInt 10 1010 1.5") {
+			require.GreaterOrEqual(t, v, 10)
+			require.Less(t, v, 1010)
+			counts[v]++
+		}
+		// The lowest key is the hottest one
+		assert.Greater(t, counts[10], counts[11])
+		assert.Greater(t, counts[11], counts[20])
+		assert.Greater(t, counts[10], samples/4)
+	})
+	t.Run("normalInt", func(t *testing.T) {
+		values := draw("normalInt 100 10")
+		assert.InDelta(t, 100, mean(values), 0.5)
+		within := 0
+		for _, v := range values {
+			if v >= 90 && v <= 110 {
+				within++
+			}
+		}
+		assert.InDelta(t, 0.68, float64(within)/samples, 0.05)
+	})
+	t.Run("exponentialInt", func(t *testing.T) {
+		values := draw("exponentialInt 0 1000 50")
+		for _, v := range values {
+			require.GreaterOrEqual(t, v, 0)
+			require.Less(t, v, 1000)
+		}
+		// Values are truncated to integers, so mean is about half lower
+		assert.InDelta(t, 49.5, mean(values), 2)
+	})
+	t.Run("hotspotInt", func(t *testing.T) {
+		hot := 0
+		for _, v := range draw("hotspotInt 0 1000 0.1 0.9") {
+			require.GreaterOrEqual(t, v, 0)
+			require.Less(t, v, 1000)
+			if v < 100 {
+				hot++
+			}
+		}
+		assert.InDelta(t, 0.9, float64(hot)/samples, 0.02)
+	})
+
+	errorTests := []struct {
+		args     string
+		errorMsg string
+	}{
+		{"zipfInt 1 100", "requires exactly 3 arguments"},
+		{"zipfInt 1 100 1", "must be greater than 1"},
+		{"zipfInt 100 1 1.5", "must be less than max value"},
+		{"normalInt 100 0", "stddev 0 must be positive"},
+		{"normalInt abc 1", "argument parsing error"},
+		{"exponentialInt 0 100 0", "must be positive"},
+		{"exponentialInt 0 100 200", "not greater than range size 100"},
+		{"hotspotInt 0 100 1 0.5", "hot fraction 1"},
+		{"hotspotInt 0 100 0.1 1.5", "hot probability 1.5"},
+	}
+	for _, tt := range errorTests {
+		_, err := GetGenerators(tt.args, nil, nil)
+		assert.ErrorContains(t, err, tt.errorMsg, tt.args)
+	}
+}
+
+func TestCounterGenerators(t *testing.T) {
+	generators, err := GetGenerators("seq, seq 100 -10, threadSeq 5 5, threadId, iteration", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, generators, 5)
+
+	first, second := NewGenContext(1, 0, 2), NewGenContext(2, 1, 2)
+	take := func(gc *GenContext) []any {
+		gc.Reset()
+		args, err := getArgs(gc, generators)
+		require.NoError(t, err)
+		return args
+	}
+
+	assert.Equal(t, []any{int64(1), int64(100), int64(5), 1, int64(0)}, take(first))
+	assert.Equal(t, []any{int64(2), int64(90), int64(5), 2, int64(0)}, take(second), "seq is shared by threads, threadSeq is not")
+	assert.Equal(t, []any{int64(3), int64(80), int64(10), 1, int64(1)}, take(first))
+
+	errorTests := []struct {
+		args     string
+		errorMsg string
+	}{
+		{"seq 1", "must start with letter"},
+		{"seq ids 1 1 1", "requires optional name and no arguments or exactly 2 arguments"},
+		{"seq ids 1 1, seq ids 2 1", `"ids" is already used with start 1 and step 1`},
+		{"threadSeq 1 0", "step cannot be zero"},
+		{"seq a 1", "argument parsing error"},
+		{"threadId 1", "does not support args"},
+		{"iteration 1", "does not support args"},
+	}
+	for _, tt := range errorTests {
+		_, err := GetGenerators(tt.args, nil, nil)
+		assert.ErrorContains(t, err, tt.errorMsg, tt.args)
+	}
+}
+
+func TestSequences(t *testing.T) {
+	seqs := NewSequences()
+	insert, err := GetGenerators("seq, seq, seq ids 10 10, threadSeq, threadSeq, threadSeq no", nil, seqs)
+	require.NoError(t, err)
+	update, err := GetGenerators("seq ids 10 10, threadSeq no", nil, seqs)
+	require.NoError(t, err)
+
+	gc := NewGenContext(1, 0, 1)
+	take := func(generators []GeneratorFunc) []any {
+		gc.Reset()
+		args, err := getArgs(gc, generators)
+		require.NoError(t, err)
+		return args
+	}
+
+	assert.Equal(t, []any{int64(1), int64(1), int64(10), int64(1), int64(1), int64(1)}, take(insert), "unnamed arguments have own counters")
+	assert.Equal(t, []any{int64(2), int64(2), int64(20), int64(2), int64(2), int64(2)}, take(insert))
+	assert.Equal(t, []any{int64(30), int64(3)}, take(update), "statements of scenario share named counters")
+}
@@ -0,0 +1,238 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	lineFormatInflux   = "influx"
+	lineFormatGraphite = "graphite"
+
+	defaultLineInterval    = 10 * time.Second
+	defaultLineMeasurement = "loadhound"
+	lineSinkQueueSize      = 64
+	lineSinkTimeout        = 5 * time.Second
+)
+
+var (
+	influxTagEscaper  = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+	graphiteInvalidRe = regexp.MustCompile(`[^A-Za-z0-9_\-]`)
+)
+
+// LineSink periodically pushes scenario interval aggregates in InfluxDB line protocol
+// or Graphite plaintext format to HTTP endpoint, TCP endpoint and/or local file.
+// Batches are written in background, so a slow endpoint doesn't delay monitors.
+type LineSink struct {
+	cfg         *LineSinkConfig
+	percentiles []float64
+	logger      *zerolog.Logger
+
+	batches chan []byte
+	done    chan struct{}
+	dropped atomic.Int64
+
+	file   *os.File
+	client *http.Client
+	conn   net.Conn
+}
+
+func NewLineSink(cfg *LineSinkConfig, percentiles []float64, logger *zerolog.Logger) (*LineSink, error) {
+	s := &LineSink{
+		cfg:         cfg,
+		percentiles: percentiles,
+		logger:      logger,
+		batches:     make(chan []byte, lineSinkQueueSize),
+		done:        make(chan struct{}),
+	}
+	if cfg.File != "" {
+		// #nosec G304 -- path to metrics file is provided by user on purpose
+		f, err := os.OpenFile(filepath.Clean(cfg.File), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open line protocol file: %w", err)
+		}
+		s.file = f
+	}
+	if cfg.URL != "" {
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line protocol url: %w", err)
+		}
+		if u.Scheme == "http" || u.Scheme == "https" {
+			s.client = &http.Client{Timeout: lineSinkTimeout}
+		}
+	}
+	go s.run()
+	return s, nil
+}
+
+// Interval gets how often aggregates are pushed
+func (s *LineSink) Interval() time.Duration {
+	if s.cfg.Interval > 0 {
+		return s.cfg.Interval
+	}
+	return defaultLineInterval
+}
+
+// Push formats scenario aggregate and queues it for writing, batch is dropped if queue is full
+func (s *LineSink) Push(scenario string, m *Metric, activeThreads int) {
+	batch := s.format(scenario, m, activeThreads)
+	select {
+	case s.batches <- batch:
+	default:
+		dropped := s.dropped.Add(1)
+		s.logger.Warn().Str("scenario_name", scenario).Int64("dropped_total", dropped).Msg("Line protocol queue is full, batch dropped")
+	}
+}
+
+// Close writes queued batches and releases file and connection
+func (s *LineSink) Close() error {
+	close(s.batches)
+	<-s.done
+
+	var err error
+	if s.file != nil {
+		err = errors.Join(err, s.file.Close())
+	}
+	if s.conn != nil {
+		err = errors.Join(err, s.conn.Close())
+	}
+	return err
+}
+
+func (s *LineSink) run() {
+	defer close(s.done)
+	for batch := range s.batches {
+		if s.file != nil {
+			if _, err := s.file.Write(batch); err != nil {
+				s.logger.Error().Err(err).Msg("Failed to write line protocol file")
+			}
+		}
+		if s.cfg.URL != "" {
+			if err := s.send(batch); err != nil {
+				s.logger.Error().Err(err).Str("url", s.cfg.URL).Msg("Failed to push line protocol batch")
+			}
+		}
+	}
+}
+
+// Send batch over HTTP or TCP, TCP connection is reopened on the next batch after failure
+func (s *LineSink) send(batch []byte) error {
+	if s.client != nil {
+		req, err := http.NewRequest(http.MethodPost, s.cfg.URL, bytes.NewReader(batch))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		if s.cfg.Token != "" {
+			req.Header.Set("Authorization", "Token "+s.cfg.Token)
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+		return nil
+	}
+
+	if s.conn == nil {
+		u, err := url.Parse(s.cfg.URL)
+		if err != nil {
+			return err
+		}
+		if s.conn, err = net.DialTimeout("tcp", u.Host, lineSinkTimeout); err != nil {
+			return err
+		}
+	}
+	if err := s.conn.SetWriteDeadline(time.Now().Add(lineSinkTimeout)); err != nil {
+		return err
+	}
+	if _, err := s.conn.Write(batch); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Format aggregate fields as lines, latencies are in milliseconds
+func (s *LineSink) format(scenario string, m *Metric, activeThreads int) []byte {
+	measurement := s.cfg.Measurement
+	if measurement == "" {
+		measurement = defaultLineMeasurement
+	}
+	if scenario == "" {
+		scenario = "unnamed"
+	}
+	ts := m.StopTime
+
+	type field struct {
+		name  string
+		value string
+		isInt bool
+	}
+	fields := []field{
+		{"qps", formatFloat(m.GetQPS()), false},
+		{"queries", strconv.FormatInt(m.QueriesTotal, 10), true},
+		{"errors", strconv.FormatInt(m.ErrorsTotal, 10), true},
+		{"rows", strconv.FormatInt(m.RowsAffected, 10), true},
+		{"active_threads", strconv.Itoa(activeThreads), true},
+	}
+	// Dot separates Graphite path, so p99.9 is written as p99_9
+	for _, p := range s.percentiles {
+		name := strings.ReplaceAll(percentileName(p), ".", "_")
+		fields = append(fields, field{name, formatFloat(toMillis(quantile(m.Td, p/100))), false})
+	}
+	fields = append(fields, field{"max", formatFloat(toMillis(quantile(m.Td, 1))), false})
+
+	var b bytes.Buffer
+	if s.cfg.Format == lineFormatGraphite {
+		prefix := measurement + "." + graphiteInvalidRe.ReplaceAllString(scenario, "_")
+		for _, f := range fields {
+			fmt.Fprintf(&b, "%s.%s %s %d\n", prefix, f.name, f.value, ts.Unix())
+		}
+		return b.Bytes()
+	}
+
+	fmt.Fprintf(&b, "%s,scenario=%s ", influxTagEscaper.Replace(measurement), influxTagEscaper.Replace(scenario))
+	for idx, f := range fields {
+		if idx > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(f.name + "=" + f.value)
+		if f.isInt {
+			b.WriteByte('i')
+		}
+	}
+	fmt.Fprintf(&b, " %d\n", ts.UnixNano())
+	return b.Bytes()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,150 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSinkAggregate(t *testing.T) *Metric {
+	m, err := NewMetric()
+	require.NoError(t, err)
+	start := time.Unix(1700000000, 0)
+	m.StartTime, m.StopTime = start, start.Add(10*time.Second)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, m.SubmitQueryResult(&QueryResult{RowsAffected: 1, ResponseTime: 2 * time.Millisecond}))
+	}
+	require.NoError(t, m.SubmitQueryResult(&QueryResult{ResponseTime: 2 * time.Millisecond, Err: assert.AnError}))
+	return m
+}
+
+func TestLineSink_format(t *testing.T) {
+	logger := zerolog.Nop()
+
+	t.Run("should format influx line protocol", func(t *testing.T) {
+		sink := &LineSink{cfg: &LineSinkConfig{}, percentiles: defaultPercentiles, logger: &logger}
+
+		line := string(sink.format("select users", newSinkAggregate(t), 4))
+
+		assert.Equal(t, `loadhound,scenario=select\ users qps=10.1,queries=101i,errors=1i,rows=100i,active_threads=4i,p50=2,p90=2,p95=2,p99=2,max=2 1700000010000000000`+"\n", line)
+	})
+
+	t.Run("should format graphite plaintext", func(t *testing.T) {
+		sink := &LineSink{cfg: &LineSinkConfig{Format: lineFormatGraphite, Measurement: "lh"}, percentiles: []float64{99.9}, logger: &logger}
+
+		lines := strings.Split(strings.TrimSpace(string(sink.format("select users", newSinkAggregate(t), 4))), "\n")
+
+		require.Len(t, lines, 7)
+		assert.Equal(t, "lh.select_users.qps 10.1 1700000010", lines[0])
+		assert.Equal(t, "lh.select_users.active_threads 4 1700000010", lines[4])
+		assert.Equal(t, "lh.select_users.p99_9 2 1700000010", lines[5])
+	})
+}
+
+func TestLineSink_Push(t *testing.T) {
+	logger := zerolog.Nop()
+
+	t.Run("should write batches to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "metrics.lp")
+		sink, err := NewLineSink(&LineSinkConfig{File: path}, defaultPercentiles, &logger)
+		require.NoError(t, err)
+
+		sink.Push("select", newSinkAggregate(t), 1)
+		sink.Push("insert", newSinkAggregate(t), 1)
+		require.NoError(t, sink.Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], "loadhound,scenario=select "))
+		assert.True(t, strings.HasPrefix(lines[1], "loadhound,scenario=insert "))
+	})
+
+	t.Run("should post batches over http", func(t *testing.T) {
+		received := make(chan string, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, "Token secret", r.Header.Get("Authorization"))
+			received <- string(body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		sink, err := NewLineSink(&LineSinkConfig{URL: server.URL + "/api/v2/write?bucket=lh", Token: "secret"}, defaultPercentiles, &logger)
+		require.NoError(t, err)
+		sink.Push("select", newSinkAggregate(t), 1)
+		require.NoError(t, sink.Close())
+
+		assert.True(t, strings.HasPrefix(<-received, "loadhound,scenario=select "))
+	})
+
+	t.Run("should write batches over tcp", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		received := make(chan string, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			received <- line
+		}()
+
+		sink, err := NewLineSink(&LineSinkConfig{URL: "tcp://" + ln.Addr().String(), Format: lineFormatGraphite}, defaultPercentiles, &logger)
+		require.NoError(t, err)
+		sink.Push("select", newSinkAggregate(t), 1)
+		require.NoError(t, sink.Close())
+
+		assert.Equal(t, "loadhound.select.qps 10.1 1700000010\n", <-received)
+	})
+}
+
+func TestMonitor_tick_LineSink(t *testing.T) {
+	logger := zerolog.Nop()
+	threads := newMonitorThreads(t, 1)
+	mon, err := NewMonitor(&logger, &RunConfig{}, &ScenarioConfig{Name: "select", Duration: time.Minute}, threads)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "metrics.lp")
+	sink, err := NewLineSink(&LineSinkConfig{File: path, Interval: 2 * time.Second}, defaultPercentiles, &logger)
+	require.NoError(t, err)
+	require.NoError(t, mon.SetLineSink(sink))
+
+	start := time.Now()
+	mon.bucket.start, mon.sinkAgg.start = start, start
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond}))
+		mon.tick(start, start.Add(time.Duration(i-1)*time.Second), start.Add(time.Duration(i)*time.Second), i == 3)
+	}
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "queries=2i")
+	assert.Contains(t, lines[1], "queries=1i")
+}
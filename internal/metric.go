@@ -9,13 +9,28 @@ package internal
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/HdrHistogram/hdrhistogram-go"
 	"github.com/caio/go-tdigest/v4"
 )
 
-const tdigestCompression = 100.0
+const (
+	tdigestCompression = 100.0
+
+	recorderTDigest = "tdigest"
+	recorderHdr     = "hdr"
+
+	// HDR histograms record response time in microseconds with 3 significant digits
+	hdrLowest  int64 = 1
+	hdrHighest int64 = int64(time.Hour / time.Microsecond)
+	hdrDigits        = 3
+
+	// Distinct messages kept as samples of each error class
+	maxErrSamples = 3
+)
 
 type Metric struct {
 	mu *sync.Mutex
@@ -23,6 +38,17 @@ type Metric struct {
 	// TDigest for percentile calculations of response time
 	Td *tdigest.TDigest
 
+	// TDigest for percentile calculations of whole iteration time
+	IterTd *tdigest.TDigest
+
+	// TDigest of time open-model arrivals waited for free worker
+	QueueTd *tdigest.TDigest
+
+	// HDR histograms of response time as measured and corrected for coordinated omission,
+	// nil unless enabled by EnableHdr
+	Hdr          *hdrhistogram.Histogram
+	CorrectedHdr *hdrhistogram.Histogram
+
 	// Timing information
 	StartTime time.Time
 	StopTime  time.Time
@@ -34,9 +60,34 @@ type Metric struct {
 	RowsAffected int64
 	QueriesTotal int64
 	ErrorsTotal  int64
+	SlowTotal    int64 // Queries slower than slow threshold of statement
 
-	// Error tracking
+	// Transaction conflicts, also included in ErrorsTotal
+	SerializationFailuresTotal int64
+	DeadlocksTotal             int64
+
+	// Open-model arrivals which found no free worker
+	DroppedTotal int64
+	DelayedTotal int64
+
+	// Errors count by class, see errorClass
 	ErrMap map[string]int64
+
+	// The first distinct error messages of each class, at most maxErrSamples
+	ErrSamples map[string][]string
+
+	// Per statement metrics, set only for scripts with several statements
+	Statements []*Metric
+
+	// Results per time bucket recorded by monitor
+	Timeseries []*TimeseriesPoint
+
+	// Set when the test was aborted or interrupted before scenario finished
+	AbortReason string
+	Interrupted bool
+
+	// Accumulates results since the last TakeInterval call, nil if intervals are not tracked
+	interval *Metric
 }
 
 func NewMetric() (*Metric, error) {
@@ -44,13 +95,63 @@ func NewMetric() (*Metric, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TDigest: %w", err)
 	}
+	iterTd, err := tdigest.New(tdigest.Compression(tdigestCompression))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TDigest: %w", err)
+	}
+	queueTd, err := tdigest.New(tdigest.Compression(tdigestCompression))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TDigest: %w", err)
+	}
 	return &Metric{
-		mu:     &sync.Mutex{},
-		Td:     td,
-		ErrMap: make(map[string]int64),
+		mu:         &sync.Mutex{},
+		Td:         td,
+		IterTd:     iterTd,
+		QueueTd:    queueTd,
+		ErrMap:     make(map[string]int64),
+		ErrSamples: make(map[string][]string),
 	}, nil
 }
 
+// NewScriptMetric creates metric which tracks intervals, with per statement breakdown
+// if script consists of more than one statement.
+func NewScriptMetric(statements int) (*Metric, error) {
+	m, err := NewMetric()
+	if err != nil {
+		return nil, err
+	}
+	if m.interval, err = NewMetric(); err != nil {
+		return nil, err
+	}
+	if statements <= 1 {
+		return m, nil
+	}
+	m.Statements = make([]*Metric, statements)
+	for idx := range m.Statements {
+		if m.Statements[idx], err = NewMetric(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// EnableHdr starts recording response times into HDR histograms, including per statement metrics.
+// Intervals keep only TDigest.
+func (m *Metric) EnableHdr() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Hdr = newHdr()
+	m.CorrectedHdr = newHdr()
+	for _, stmt := range m.Statements {
+		stmt.EnableHdr()
+	}
+}
+
+func newHdr() *hdrhistogram.Histogram {
+	return hdrhistogram.New(hdrLowest, hdrHighest, hdrDigits)
+}
+
 func (m *Metric) SetStartTime(at time.Time) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -73,6 +174,9 @@ func (m *Metric) AddIter() {
 	defer m.mu.Unlock()
 
 	m.IterationsTotal++
+	if m.interval != nil {
+		m.interval.IterationsTotal++
+	}
 }
 
 func (m *Metric) AddThread() {
@@ -82,6 +186,20 @@ func (m *Metric) AddThread() {
 	m.ThreadsTotal++
 }
 
+func (m *Metric) AddDropped() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.DroppedTotal++
+}
+
+func (m *Metric) AddDelayed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.DelayedTotal++
+}
+
 func (m *Metric) SubmitQueryResult(q *QueryResult) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -89,36 +207,214 @@ func (m *Metric) SubmitQueryResult(q *QueryResult) error {
 	if q == nil {
 		return nil
 	}
+	if m.interval != nil {
+		if err := m.interval.submit(q); err != nil {
+			return err
+		}
+	}
+	return m.submit(q)
+}
 
+func (m *Metric) submit(q *QueryResult) error {
 	m.RowsAffected += q.RowsAffected
 	m.QueriesTotal++
+	if q.Slow {
+		m.SlowTotal++
+	}
 	if q.Err != nil {
-		m.ErrorsTotal++
-		m.ErrMap[q.Err.Error()]++
+		m.addError(q.Err)
+	}
+	if m.Hdr != nil {
+		if err := recordHdr(m.Hdr, q.ResponseTime); err != nil {
+			return err
+		}
+		if err := recordCorrectedHdr(m.CorrectedHdr, q.ResponseTime+q.ScheduleDelay, q.ExpectedInterval); err != nil {
+			return err
+		}
 	}
 	return m.Td.Add(float64(q.ResponseTime))
 }
 
+// Record duration into HDR histogram, values out of trackable range are clamped
+func recordHdr(h *hdrhistogram.Histogram, d time.Duration) error {
+	v := min(max(d.Microseconds(), hdrLowest), hdrHighest)
+	return h.RecordValue(v)
+}
+
+// Record duration into HDR histogram together with values of iterations which should have started
+// every expected interval while it lasted, i.e. d-interval, d-2*interval and so on down to interval
+func recordCorrectedHdr(h *hdrhistogram.Histogram, d, expectedInterval time.Duration) error {
+	if expectedInterval <= 0 {
+		return recordHdr(h, d)
+	}
+	v := min(max(d.Microseconds(), hdrLowest), hdrHighest)
+	return h.RecordCorrectedValue(v, max(expectedInterval.Microseconds(), hdrLowest))
+}
+
+// TakeInterval returns results accumulated since the previous call and starts new interval.
+// Returned metric is empty if intervals are not tracked.
+func (m *Metric) TakeInterval() (*Metric, error) {
+	fresh, err := NewMetric()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.interval == nil {
+		return fresh, nil
+	}
+	taken := m.interval
+	m.interval = fresh
+	return taken, nil
+}
+
+// AddError records failed operation which is not a statement query, e.g. transaction begin or commit.
+// The operation is counted as query attempt, so failed rate stays within 100%.
+func (m *Metric) AddError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.QueriesTotal++
+	m.addError(err)
+	if m.interval != nil {
+		m.interval.QueriesTotal++
+		m.interval.addError(err)
+	}
+}
+
+func (m *Metric) addError(err error) {
+	m.ErrorsTotal++
+	class := errorClass(err)
+	m.ErrMap[class]++
+	m.addErrSample(class, err.Error())
+	if isSerializationFailure(err) {
+		m.SerializationFailuresTotal++
+	}
+	if isDeadlock(err) {
+		m.DeadlocksTotal++
+	}
+}
+
+// Keep message as sample of error class, unless class already has it or has enough samples
+func (m *Metric) addErrSample(class, msg string) {
+	samples := m.ErrSamples[class]
+	if len(samples) < maxErrSamples && !slices.Contains(samples, msg) {
+		m.ErrSamples[class] = append(samples, msg)
+	}
+}
+
+// SubmitIteration records duration of the whole thread iteration
+func (m *Metric) SubmitIteration(d time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.IterTd.Add(float64(d))
+}
+
+// SubmitQueueDelay records how long open-model arrival waited for free worker
+func (m *Metric) SubmitQueueDelay(d time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.QueueTd.Add(float64(max(d, 0)))
+}
+
+// Merge adds counters and digests from snapshot into metric
+func (m *Metric) Merge(snapshot *Metric) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.IterationsTotal += snapshot.IterationsTotal
+	m.RowsAffected += snapshot.RowsAffected
+	m.QueriesTotal += snapshot.QueriesTotal
+	m.ErrorsTotal += snapshot.ErrorsTotal
+	m.SlowTotal += snapshot.SlowTotal
+	m.SerializationFailuresTotal += snapshot.SerializationFailuresTotal
+	m.DeadlocksTotal += snapshot.DeadlocksTotal
+	if err := m.Td.Merge(snapshot.Td); err != nil {
+		return err
+	}
+	if err := m.IterTd.Merge(snapshot.IterTd); err != nil {
+		return err
+	}
+	if err := m.QueueTd.Merge(snapshot.QueueTd); err != nil {
+		return err
+	}
+	if m.Hdr != nil && snapshot.Hdr != nil {
+		m.Hdr.Merge(snapshot.Hdr)
+		m.CorrectedHdr.Merge(snapshot.CorrectedHdr)
+	}
+	if len(snapshot.ErrMap) != 0 {
+		for k, v := range snapshot.ErrMap {
+			m.ErrMap[k] += v
+		}
+	}
+	for k, samples := range snapshot.ErrSamples {
+		for _, msg := range samples {
+			m.addErrSample(k, msg)
+		}
+	}
+	for idx, stmtSnapshot := range snapshot.Statements {
+		if idx >= len(m.Statements) {
+			break
+		}
+		if err := m.Statements[idx].Merge(stmtSnapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *Metric) GetSnapshot() *Metric {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	tdCopy := m.Td.Clone()
+	iterTdCopy := m.IterTd.Clone()
+	queueTdCopy := m.QueueTd.Clone()
+
+	var statementsCopy []*Metric
+	for _, stmt := range m.Statements {
+		statementsCopy = append(statementsCopy, stmt.GetSnapshot())
+	}
 
 	errMapCopy := make(map[string]int64, len(m.ErrMap))
 	for k, v := range m.ErrMap {
 		errMapCopy[k] = v
 	}
+	errSamplesCopy := make(map[string][]string, len(m.ErrSamples))
+	for k, v := range m.ErrSamples {
+		errSamplesCopy[k] = slices.Clone(v)
+	}
+
+	var hdrCopy, correctedHdrCopy *hdrhistogram.Histogram
+	if m.Hdr != nil {
+		hdrCopy = hdrhistogram.Import(m.Hdr.Export())
+		correctedHdrCopy = hdrhistogram.Import(m.CorrectedHdr.Export())
+	}
 
 	return &Metric{
-		StartTime:       m.StartTime,
-		StopTime:        m.StopTime,
-		IterationsTotal: m.IterationsTotal,
-		RowsAffected:    m.RowsAffected,
-		QueriesTotal:    m.QueriesTotal,
-		ErrorsTotal:     m.ErrorsTotal,
-		Td:              tdCopy,
-		ErrMap:          errMapCopy,
+		StartTime:                  m.StartTime,
+		StopTime:                   m.StopTime,
+		IterationsTotal:            m.IterationsTotal,
+		RowsAffected:               m.RowsAffected,
+		QueriesTotal:               m.QueriesTotal,
+		ErrorsTotal:                m.ErrorsTotal,
+		SlowTotal:                  m.SlowTotal,
+		SerializationFailuresTotal: m.SerializationFailuresTotal,
+		DeadlocksTotal:             m.DeadlocksTotal,
+		DroppedTotal:               m.DroppedTotal,
+		DelayedTotal:               m.DelayedTotal,
+		Td:                         tdCopy,
+		IterTd:                     iterTdCopy,
+		QueueTd:                    queueTdCopy,
+		Hdr:                        hdrCopy,
+		CorrectedHdr:               correctedHdrCopy,
+		ErrMap:                     errMapCopy,
+		ErrSamples:                 errSamplesCopy,
+		Statements:                 statementsCopy,
 	}
 }
 
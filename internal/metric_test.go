@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -36,6 +37,133 @@ func TestNewMetric(t *testing.T) {
 	})
 }
 
+func TestNewScriptMetric(t *testing.T) {
+	t.Run("should not create statements breakdown for single statement", func(t *testing.T) {
+		metric, err := NewScriptMetric(1)
+
+		require.NoError(t, err)
+		assert.Nil(t, metric.Statements)
+	})
+
+	t.Run("should create metric per statement", func(t *testing.T) {
+		metric, err := NewScriptMetric(3)
+
+		require.NoError(t, err)
+		assert.Len(t, metric.Statements, 3)
+		for _, stmt := range metric.Statements {
+			assert.NotNil(t, stmt.Td)
+		}
+	})
+}
+
+func TestMetric_Merge(t *testing.T) {
+	threadMetric, err := NewScriptMetric(2)
+	require.NoError(t, err)
+	require.NoError(t, threadMetric.SubmitQueryResult(&QueryResult{RowsAffected: 2, ResponseTime: time.Millisecond, Slow: true}))
+	require.NoError(t, threadMetric.Statements[0].SubmitQueryResult(&QueryResult{RowsAffected: 2, ResponseTime: time.Millisecond}))
+	require.NoError(t, threadMetric.SubmitIteration(3*time.Millisecond))
+	threadMetric.AddIter()
+
+	scenarioMetric, err := NewScriptMetric(2)
+	require.NoError(t, err)
+
+	require.NoError(t, scenarioMetric.Merge(threadMetric.GetSnapshot()))
+	require.NoError(t, scenarioMetric.Merge(threadMetric.GetSnapshot()))
+
+	assert.Equal(t, int64(2), scenarioMetric.IterationsTotal)
+	assert.Equal(t, int64(2), scenarioMetric.QueriesTotal)
+	assert.Equal(t, int64(4), scenarioMetric.RowsAffected)
+	assert.Equal(t, int64(2), scenarioMetric.SlowTotal)
+	assert.Equal(t, uint64(2), scenarioMetric.IterTd.Count())
+	assert.Equal(t, int64(2), scenarioMetric.Statements[0].QueriesTotal)
+	assert.Equal(t, int64(0), scenarioMetric.Statements[1].QueriesTotal)
+}
+
+func TestMetric_CorrectedHdr_Stall(t *testing.T) {
+	m, err := NewMetric()
+	require.NoError(t, err)
+	m.EnableHdr()
+
+	// 100ms pacing, 99 fast queries and one 5s stall which hides 49 iterations
+	for i := 0; i < 99; i++ {
+		require.NoError(t, m.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond, ExpectedInterval: 100 * time.Millisecond}))
+	}
+	require.NoError(t, m.SubmitQueryResult(&QueryResult{ResponseTime: 5 * time.Second, ExpectedInterval: 100 * time.Millisecond}))
+
+	assert.Equal(t, int64(100), m.Hdr.TotalCount())
+	assert.Less(t, hdrDuration(m.Hdr.ValueAtPercentile(99)), 2*time.Millisecond)
+
+	assert.Equal(t, int64(149), m.CorrectedHdr.TotalCount())
+	assert.Greater(t, hdrDuration(m.CorrectedHdr.ValueAtPercentile(99)), 4*time.Second)
+	assert.Greater(t, hdrDuration(m.CorrectedHdr.ValueAtPercentile(90)), 3*time.Second)
+}
+
+func TestMetric_EnableHdr(t *testing.T) {
+	threadMetric, err := NewScriptMetric(1)
+	require.NoError(t, err)
+	threadMetric.EnableHdr()
+	require.NoError(t, threadMetric.SubmitQueryResult(&QueryResult{ResponseTime: 2 * time.Millisecond}))
+	require.NoError(t, threadMetric.SubmitQueryResult(&QueryResult{ResponseTime: 2 * time.Millisecond, ScheduleDelay: 3 * time.Second}))
+	require.NoError(t, threadMetric.SubmitQueryResult(&QueryResult{ResponseTime: 2 * time.Hour}))
+
+	assert.Equal(t, int64(3), threadMetric.Hdr.TotalCount())
+	assert.True(t, threadMetric.Hdr.ValuesAreEquivalent(hdrHighest, threadMetric.Hdr.Max()), "values above range are clamped")
+	assert.True(t, threadMetric.CorrectedHdr.ValuesAreEquivalent(3002000, threadMetric.CorrectedHdr.ValueAtPercentile(50)))
+
+	scenarioMetric, err := NewScriptMetric(1)
+	require.NoError(t, err)
+	scenarioMetric.EnableHdr()
+	require.NoError(t, scenarioMetric.Merge(threadMetric.GetSnapshot()))
+	require.NoError(t, scenarioMetric.Merge(threadMetric.GetSnapshot()))
+
+	assert.Equal(t, int64(6), scenarioMetric.Hdr.TotalCount())
+	assert.Equal(t, int64(6), scenarioMetric.CorrectedHdr.TotalCount())
+	assert.Equal(t, int64(3), threadMetric.Hdr.TotalCount(), "snapshot must not share histogram with thread")
+
+	script, err := NewScriptMetric(2)
+	require.NoError(t, err)
+	script.EnableHdr()
+	require.NoError(t, script.Statements[1].SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond}))
+	assert.Equal(t, int64(1), script.Statements[1].Hdr.TotalCount(), "statements use the same recorder as scenario")
+}
+
+func TestMetric_TakeInterval(t *testing.T) {
+	t.Run("should return results since previous call", func(t *testing.T) {
+		metric, err := NewScriptMetric(1)
+		require.NoError(t, err)
+
+		require.NoError(t, metric.SubmitQueryResult(&QueryResult{RowsAffected: 1, ResponseTime: time.Millisecond}))
+		metric.AddIter()
+		metric.AddError(assert.AnError)
+
+		first, err := metric.TakeInterval()
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), first.QueriesTotal, "failed operation is counted as attempt")
+		assert.Equal(t, int64(1), first.IterationsTotal)
+		assert.Equal(t, int64(1), first.ErrorsTotal)
+
+		require.NoError(t, metric.SubmitQueryResult(&QueryResult{RowsAffected: 1, ResponseTime: time.Millisecond}))
+
+		second, err := metric.TakeInterval()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), second.QueriesTotal)
+		assert.Equal(t, int64(0), second.ErrorsTotal)
+
+		// Totals are not affected by intervals
+		assert.Equal(t, int64(3), metric.QueriesTotal)
+	})
+
+	t.Run("should return empty interval if intervals are not tracked", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+		require.NoError(t, metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond}))
+
+		interval, err := metric.TakeInterval()
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), interval.QueriesTotal)
+	})
+}
+
 func TestMetric_SetStartTime(t *testing.T) {
 	metric, err := NewMetric()
 	require.NoError(t, err)
@@ -187,11 +315,12 @@ func TestMetric_SubmitQueryResult(t *testing.T) {
 		assert.Equal(t, int64(2), metric.QueriesTotal) // Previous test added 1
 		assert.Equal(t, int64(1), metric.ErrorsTotal)
 		assert.Equal(t, int64(5), metric.RowsAffected) // Same as previous
-		assert.Equal(t, int64(1), metric.ErrMap[testErr.Error()])
+		assert.Equal(t, int64(1), metric.ErrMap["other"])
+		assert.Equal(t, []string{"connection timeout"}, metric.ErrSamples["other"])
 	})
 
 	t.Run("should accumulate multiple errors of same type", func(t *testing.T) {
-		testErr := errors.New("connection timeout")
+		testErr := errors.New("connection reset")
 		queryResult := &QueryResult{
 			RowsAffected: 2,
 			ResponseTime: 75 * time.Millisecond,
@@ -204,11 +333,12 @@ func TestMetric_SubmitQueryResult(t *testing.T) {
 		assert.Equal(t, int64(3), metric.QueriesTotal)
 		assert.Equal(t, int64(2), metric.ErrorsTotal)
 		assert.Equal(t, int64(7), metric.RowsAffected)
-		assert.Equal(t, int64(2), metric.ErrMap[testErr.Error()])
+		assert.Equal(t, int64(2), metric.ErrMap["other"])
+		assert.Equal(t, []string{"connection timeout", "connection reset"}, metric.ErrSamples["other"], "distinct messages are kept as samples")
 	})
 
 	t.Run("should handle different error types", func(t *testing.T) {
-		differentErr := errors.New("syntax error")
+		differentErr := &pq.Error{Code: "42601", Message: "syntax error"}
 		queryResult := &QueryResult{
 			RowsAffected: 1,
 			ResponseTime: 25 * time.Millisecond,
@@ -221,8 +351,18 @@ func TestMetric_SubmitQueryResult(t *testing.T) {
 		assert.Equal(t, int64(4), metric.QueriesTotal)
 		assert.Equal(t, int64(3), metric.ErrorsTotal)
 		assert.Equal(t, int64(8), metric.RowsAffected)
-		assert.Equal(t, int64(2), metric.ErrMap["connection timeout"])
-		assert.Equal(t, int64(1), metric.ErrMap[differentErr.Error()])
+		assert.Equal(t, int64(2), metric.ErrMap["other"])
+		assert.Equal(t, int64(1), metric.ErrMap["sqlstate_42601"])
+	})
+
+	t.Run("should group errors which differ only in value", func(t *testing.T) {
+		for _, id := range []string{"1", "2", "3"} {
+			err := metric.SubmitQueryResult(&QueryResult{Err: &pq.Error{Code: "23505", Message: "duplicate key value", Detail: "Key (id)=(" + id + ") already exists."}})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int64(3), metric.ErrMap["sqlstate_23505"])
+		assert.Len(t, metric.ErrMap, 3)
 	})
 }
 
@@ -528,7 +668,7 @@ func TestMetric_ConcurrencySafety(t *testing.T) {
 		// Verify error tracking
 		expectedErrors := int64(numGoroutines * (operationsPerGoroutine / 10)) // Every 10th operation fails
 		assert.Equal(t, expectedErrors, metric.ErrorsTotal)
-		assert.Equal(t, expectedErrors, metric.ErrMap["test error"])
+		assert.Equal(t, expectedErrors, metric.ErrMap["other"])
 
 		// Test concurrent snapshots
 		snapshot1 := metric.GetSnapshot()
@@ -538,3 +678,43 @@ func TestMetric_ConcurrencySafety(t *testing.T) {
 		assert.Equal(t, snapshot1.ErrorsTotal, snapshot2.ErrorsTotal)
 	})
 }
+
+func TestMetric_MergeErrors(t *testing.T) {
+	threadMetric, err := NewMetric()
+	require.NoError(t, err)
+	require.NoError(t, threadMetric.SubmitQueryResult(&QueryResult{Err: assert.AnError, ResponseTime: time.Millisecond}))
+
+	scenarioMetric, err := NewMetric()
+	require.NoError(t, err)
+	require.NoError(t, scenarioMetric.Merge(threadMetric.GetSnapshot()))
+	require.NoError(t, scenarioMetric.Merge(threadMetric.GetSnapshot()))
+
+	// Errors of the same kind from several threads are summed up
+	var errs int64
+	for _, v := range scenarioMetric.ErrMap {
+		errs += v
+	}
+	assert.Equal(t, int64(2), scenarioMetric.ErrorsTotal)
+	assert.Equal(t, int64(2), errs)
+}
+
+func TestMetric_ErrSamples(t *testing.T) {
+	threadMetric, err := NewMetric()
+	require.NoError(t, err)
+	for _, msg := range []string{"sql: no rows", "feeder exhausted", "sql: no rows", "generator failed", "sql: closed"} {
+		require.NoError(t, threadMetric.SubmitQueryResult(&QueryResult{Err: errors.New(msg), ResponseTime: time.Millisecond}))
+	}
+	assert.Equal(t, []string{"sql: no rows", "feeder exhausted", "generator failed"}, threadMetric.ErrSamples["other"])
+
+	otherMetric, err := NewMetric()
+	require.NoError(t, err)
+	require.NoError(t, otherMetric.SubmitQueryResult(&QueryResult{Err: errors.New("sql: closed"), ResponseTime: time.Millisecond}))
+
+	scenarioMetric, err := NewMetric()
+	require.NoError(t, err)
+	require.NoError(t, scenarioMetric.Merge(otherMetric.GetSnapshot()))
+	require.NoError(t, scenarioMetric.Merge(threadMetric.GetSnapshot()))
+
+	// Samples of threads are combined up to the limit
+	assert.Equal(t, []string{"sql: closed", "sql: no rows", "feeder exhausted"}, scenarioMetric.ErrSamples["other"])
+}
@@ -0,0 +1,403 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/caio/go-tdigest/v4"
+	"github.com/rs/zerolog"
+)
+
+const (
+	monitorInterval         time.Duration = time.Second
+	defaultAbortWindow      time.Duration = 10 * time.Second
+	defaultProgressInterval time.Duration = 5 * time.Second
+
+	defaultTimeseriesInterval time.Duration = time.Second
+)
+
+// AbortError is a cause of workflow cancellation when abort threshold is not met
+type AbortError struct {
+	Reason string
+}
+
+func (e *AbortError) Error() string {
+	return "test aborted: " + e.Reason
+}
+
+// Monitor periodically collects interval results from scenario threads while scenario runs,
+// records time-series, prints progress summary and aborts the workflow when one of abort thresholds is not met.
+type Monitor struct {
+	logger  *zerolog.Logger
+	cfg     *ScenarioConfig
+	threads []*Thread
+
+	percentiles []float64 // Reported in time-series and progress
+	abortChecks []*Threshold
+	abortWindow time.Duration
+	aborted     bool
+
+	window []*Metric // Interval results within abort window
+	total  *Metric   // Results since scenario start
+
+	progress    *aggregate // Nil if progress is not printed
+	progressOut io.Writer
+
+	bucket     *aggregate
+	Timeseries []*TimeseriesPoint
+
+	sink    *LineSink // Nil if aggregates are not pushed
+	sinkAgg *aggregate
+
+	pool *PoolSampler // Nil if pool stats are not printed
+}
+
+// aggregate accumulates interval results over longer period
+type aggregate struct {
+	every time.Duration
+	start time.Time
+	m     *Metric
+}
+
+func newAggregate(every time.Duration) (*aggregate, error) {
+	m, err := NewMetric()
+	if err != nil {
+		return nil, err
+	}
+	return &aggregate{every: every, m: m}, nil
+}
+
+// Get accumulated results when period is over or force is set and start new period, nil otherwise
+func (a *aggregate) take(now time.Time, force bool) (*Metric, error) {
+	// Half of monitor tick tolerates ticker jitter
+	if !force && now.Sub(a.start) < a.every-monitorInterval/2 {
+		return nil, nil
+	}
+	fresh, err := NewMetric()
+	if err != nil {
+		return nil, err
+	}
+	taken := a.m
+	taken.StartTime, taken.StopTime = a.start, now
+	a.m, a.start = fresh, now
+	return taken, nil
+}
+
+func NewMonitor(logger *zerolog.Logger, runCfg *RunConfig, cfg *ScenarioConfig, threads []*Thread) (*Monitor, error) {
+	total, err := NewMetric()
+	if err != nil {
+		return nil, err
+	}
+	mon := &Monitor{
+		logger:      logger,
+		cfg:         cfg,
+		threads:     threads,
+		abortWindow: defaultAbortWindow,
+		total:       total,
+		percentiles: reportPercentiles(runCfg),
+		progressOut: os.Stdout,
+	}
+	for _, thCfg := range []*ThresholdsConfig{runCfg.ThresholdsConfig, cfg.ThresholdsConfig} {
+		if thCfg == nil {
+			continue
+		}
+		for _, expr := range thCfg.Abort {
+			th, err := ParseThreshold(expr)
+			if err != nil {
+				return nil, err
+			}
+			mon.abortChecks = append(mon.abortChecks, th)
+		}
+		if thCfg.AbortWindow > 0 {
+			mon.abortWindow = thCfg.AbortWindow
+		}
+	}
+
+	if progressEnabled(runCfg) {
+		interval := defaultProgressInterval
+		if cfgInterval := runCfg.OutputConfig.ProgressConfig.Interval; cfgInterval > 0 {
+			interval = cfgInterval
+		}
+		if mon.progress, err = newAggregate(interval); err != nil {
+			return nil, err
+		}
+	}
+
+	if mon.bucket, err = newAggregate(timeseriesInterval(runCfg)); err != nil {
+		return nil, err
+	}
+	return mon, nil
+}
+
+// SetLineSink makes monitor push scenario aggregates to sink with sink interval
+func (mon *Monitor) SetLineSink(sink *LineSink) error {
+	agg, err := newAggregate(sink.Interval())
+	if err != nil {
+		return err
+	}
+	mon.sink, mon.sinkAgg = sink, agg
+	return nil
+}
+
+// SetPoolSampler makes monitor print connection pool usage in progress lines
+func (mon *Monitor) SetPoolSampler(pool *PoolSampler) {
+	mon.pool = pool
+}
+
+// Run collects intervals until ctx is done, abort is called when abort threshold is not met.
+// Results collected after the last tick are flushed into time-series when ctx is done.
+func (mon *Monitor) Run(ctx context.Context, abort context.CancelCauseFunc) {
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+
+	startAt := time.Now()
+	mon.bucket.start = startAt
+	if mon.progress != nil {
+		mon.progress.start = startAt
+	}
+	if mon.sinkAgg != nil {
+		mon.sinkAgg.start = startAt
+	}
+
+	intervalStart := startAt
+	for {
+		select {
+		case <-ctx.Done():
+			mon.tick(startAt, intervalStart, time.Now(), true)
+			return
+		case now := <-ticker.C:
+			if !mon.tick(startAt, intervalStart, now, false) {
+				continue
+			}
+			intervalStart = now
+
+			if mon.aborted {
+				continue
+			}
+			if reason := mon.checkAbort(); reason != "" {
+				mon.logger.Warn().Str("reason", reason).Msg("Abort threshold is not met, stopping test")
+				mon.aborted = true
+				abort(&AbortError{Reason: reason})
+			}
+		}
+	}
+}
+
+// Collect interval results, record time-series bucket, push aggregates and print progress when their periods are over
+func (mon *Monitor) tick(startAt, intervalStart, now time.Time, final bool) bool {
+	iv, err := mon.collect(intervalStart, now)
+	if err != nil {
+		mon.logger.Error().Err(err).Msg("Failed to collect interval metrics")
+		return false
+	}
+
+	if err := mon.bucket.m.Merge(iv); err != nil {
+		mon.logger.Error().Err(err).Msg("Failed to merge time-series metrics")
+	}
+	bucket, err := mon.bucket.take(now, final)
+	if err != nil {
+		mon.logger.Error().Err(err).Msg("Failed to take time-series bucket")
+	}
+	if bucket != nil && bucket.StopTime.After(bucket.StartTime) {
+		mon.Timeseries = append(mon.Timeseries, newTimeseriesPoint(bucket, mon.activeThreads(), mon.percentiles))
+	}
+
+	if mon.sink != nil {
+		if err := mon.sinkAgg.m.Merge(iv); err != nil {
+			mon.logger.Error().Err(err).Msg("Failed to merge line protocol metrics")
+		}
+		agg, err := mon.sinkAgg.take(now, final)
+		if err != nil {
+			mon.logger.Error().Err(err).Msg("Failed to take line protocol metrics")
+		}
+		if agg != nil && agg.StopTime.After(agg.StartTime) {
+			mon.sink.Push(mon.cfg.Name, agg, mon.activeThreads())
+		}
+	}
+
+	if mon.progress == nil || final {
+		return true
+	}
+	if err := mon.progress.m.Merge(iv); err != nil {
+		mon.logger.Error().Err(err).Msg("Failed to merge progress metrics")
+	}
+	p, err := mon.progress.take(now, false)
+	if err != nil {
+		mon.logger.Error().Err(err).Msg("Failed to take progress metrics")
+	}
+	if p != nil {
+		mon.printProgress(p, now.Sub(startAt))
+	}
+	return true
+}
+
+func newTimeseriesPoint(bucket *Metric, activeThreads int, percentiles []float64) *TimeseriesPoint {
+	return &TimeseriesPoint{
+		Time:          bucket.StartTime,
+		QPS:           bucket.GetQPS(),
+		Queries:       bucket.QueriesTotal,
+		Errors:        bucket.ErrorsTotal,
+		RowsAffected:  bucket.RowsAffected,
+		ActiveThreads: activeThreads,
+		Max:           toMillis(quantile(bucket.Td, 1)),
+		Percentiles:   getTimeseriesPercentiles(bucket.Td, percentiles),
+		ErrorRates:    errorRates(bucket),
+	}
+}
+
+// Get latencies in milliseconds at percentiles
+func getTimeseriesPercentiles(td *tdigest.TDigest, percentiles []float64) []*TimeseriesPercentile {
+	res := make([]*TimeseriesPercentile, 0, len(percentiles))
+	for _, p := range percentiles {
+		res = append(res, &TimeseriesPercentile{Name: percentileName(p), Value: toMillis(quantile(td, p/100))})
+	}
+	return res
+}
+
+// Get errors per second of each error class in bucket, nil if there are no errors
+func errorRates(bucket *Metric) map[string]float64 {
+	seconds := bucket.StopTime.Sub(bucket.StartTime).Seconds()
+	if len(bucket.ErrMap) == 0 || seconds <= 0 {
+		return nil
+	}
+	rates := make(map[string]float64, len(bucket.ErrMap))
+	for class, count := range bucket.ErrMap {
+		rates[class] = float64(count) / seconds
+	}
+	return rates
+}
+
+// Get latency quantile from digest, zero if digest is empty
+func quantile(td *tdigest.TDigest, q float64) time.Duration {
+	v := td.Quantile(q)
+	if math.IsNaN(v) {
+		return 0
+	}
+	return time.Duration(v)
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Take interval results from all threads and keep them within abort window
+func (mon *Monitor) collect(start, stop time.Time) (*Metric, error) {
+	iv, err := NewMetric()
+	if err != nil {
+		return nil, err
+	}
+	for _, th := range mon.threads {
+		taken, err := th.Metric.TakeInterval()
+		if err != nil {
+			return nil, err
+		}
+		if err := iv.Merge(taken); err != nil {
+			return nil, err
+		}
+	}
+	iv.StartTime, iv.StopTime = start, stop
+
+	if err := mon.total.Merge(iv); err != nil {
+		return nil, err
+	}
+	mon.window = append(mon.window, iv)
+	for len(mon.window) > 0 && stop.Sub(mon.window[0].StartTime) > mon.abortWindow {
+		mon.window = mon.window[1:]
+	}
+	return iv, nil
+}
+
+// Print one summary line with results since the previous line
+func (mon *Monitor) printProgress(p *Metric, elapsed time.Duration) {
+	line := fmt.Sprintf("[%s] elapsed: %s", mon.cfg.Name, elapsed.Round(time.Second))
+	if planned := plannedDuration(mon.cfg); planned > 0 {
+		line += fmt.Sprintf(" remaining: %s", max(planned-elapsed, 0).Round(time.Second))
+	} else {
+		line += fmt.Sprintf(" iterations: %d/%d", mon.total.IterationsTotal, int64(mon.cfg.Iterations)*int64(len(mon.threads)))
+	}
+	line += fmt.Sprintf(" threads: %d/%d qps: %.2f", mon.activeThreads(), len(mon.threads), p.GetQPS())
+	for _, pct := range mon.percentiles {
+		line += fmt.Sprintf(" %s: %s", percentileName(pct), quantile(p.Td, pct/100).Round(time.Microsecond))
+	}
+	line += fmt.Sprintf(" errors: %d", p.ErrorsTotal)
+	if mon.pool != nil {
+		stats := mon.pool.Current()
+		line += fmt.Sprintf(" conns in use: %d/%d waits: %d wait time: %s",
+			stats.InUse, stats.OpenConnections,
+			stats.WaitCount,
+			stats.WaitDuration.Round(time.Microsecond))
+	}
+	fmt.Fprintln(mon.progressOut, line)
+}
+
+func (mon *Monitor) activeThreads() int {
+	active := 0
+	for _, th := range mon.threads {
+		if th.IsActive() {
+			active++
+		}
+	}
+	return active
+}
+
+// Get reason of abort if any abort threshold is not met, empty string otherwise
+func (mon *Monitor) checkAbort() string {
+	if len(mon.abortChecks) == 0 || len(mon.window) == 0 {
+		return ""
+	}
+	window, err := NewMetric()
+	if err != nil {
+		mon.logger.Error().Err(err).Msg("Failed to create window metric")
+		return ""
+	}
+	for _, iv := range mon.window {
+		if err := window.Merge(iv); err != nil {
+			mon.logger.Error().Err(err).Msg("Failed to merge window metric")
+			return ""
+		}
+	}
+	window.StartTime, window.StopTime = mon.window[0].StartTime, mon.window[len(mon.window)-1].StopTime
+
+	for _, th := range mon.abortChecks {
+		// Skip latency checks while window has no queries
+		if th.kind == thresholdLatency && window.QueriesTotal == 0 {
+			continue
+		}
+		res := th.EvaluateWindow(window, mon.total)
+		if !res.Passed {
+			return fmt.Sprintf("scenario %q: %s (actual: %s)", mon.cfg.Name, res.Expr, res.Actual)
+		}
+	}
+	return ""
+}
+
+// Get planned scenario duration, zero for iteration-based scenario
+func plannedDuration(cfg *ScenarioConfig) time.Duration {
+	if len(cfg.Stages) > 0 {
+		return stagesDuration(cfg.Stages)
+	}
+	return cfg.Duration
+}
+
+// Get size of time-series buckets
+func timeseriesInterval(runCfg *RunConfig) time.Duration {
+	if runCfg.OutputConfig != nil && runCfg.OutputConfig.ReportConfig != nil && runCfg.OutputConfig.ReportConfig.TimeseriesInterval > 0 {
+		return runCfg.OutputConfig.ReportConfig.TimeseriesInterval
+	}
+	return defaultTimeseriesInterval
+}
+
+func progressEnabled(runCfg *RunConfig) bool {
+	return runCfg.OutputConfig != nil && runCfg.OutputConfig.ProgressConfig != nil && runCfg.OutputConfig.ProgressConfig.ToConsole
+}
@@ -0,0 +1,291 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMonitorThreads(t *testing.T, n int) []*Thread {
+	logger := zerolog.Nop()
+	threads, err := InitThreads(n, NewSharedId(), &ScriptExecutor{Statements: []*StatementExecutor{{Query: "SELECT 1"}}}, &logger)
+	require.NoError(t, err)
+	return threads
+}
+
+func TestNewMonitor(t *testing.T) {
+	logger := zerolog.Nop()
+
+	t.Run("should combine global and scenario abort checks", func(t *testing.T) {
+		global := &ThresholdsConfig{Abort: []string{"errors < 1000"}}
+		cfg := &ScenarioConfig{ThresholdsConfig: &ThresholdsConfig{Abort: []string{"failed_rate < 20%"}, AbortWindow: 5 * time.Second}}
+
+		mon, err := NewMonitor(&logger, &RunConfig{ThresholdsConfig: global}, cfg, nil)
+
+		require.NoError(t, err)
+		assert.Len(t, mon.abortChecks, 2)
+		assert.Equal(t, 5*time.Second, mon.abortWindow)
+	})
+
+	t.Run("should use default abort window", func(t *testing.T) {
+		mon, err := NewMonitor(&logger, &RunConfig{}, &ScenarioConfig{}, nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, mon.abortChecks)
+		assert.Equal(t, defaultAbortWindow, mon.abortWindow)
+		assert.Nil(t, mon.progress)
+		assert.Equal(t, defaultTimeseriesInterval, mon.bucket.every)
+	})
+
+	t.Run("should enable progress with default interval", func(t *testing.T) {
+		runCfg := &RunConfig{OutputConfig: &OutputConfig{ProgressConfig: &ProgressConfig{ToConsole: true}}}
+		mon, err := NewMonitor(&logger, runCfg, &ScenarioConfig{}, nil)
+
+		require.NoError(t, err)
+		require.NotNil(t, mon.progress)
+		assert.Equal(t, defaultProgressInterval, mon.progress.every)
+	})
+}
+
+func TestMonitor_printProgress(t *testing.T) {
+	logger := zerolog.Nop()
+	runCfg := &RunConfig{OutputConfig: &OutputConfig{
+		ProgressConfig: &ProgressConfig{ToConsole: true, Interval: 2 * time.Second},
+		ReportConfig:   &ReportConfig{Percentiles: []float64{50, 99.9}},
+	}}
+
+	t.Run("should print remaining time for duration scenario", func(t *testing.T) {
+		threads := newMonitorThreads(t, 2)
+		threads[0].active.Store(true)
+		mon, err := NewMonitor(&logger, runCfg, &ScenarioConfig{Name: "select", Duration: time.Minute}, threads)
+		require.NoError(t, err)
+		var out bytes.Buffer
+		mon.progressOut = &out
+
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond}))
+		}
+		require.NoError(t, threads[1].Metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond, Err: assert.AnError}))
+		iv, err := mon.collect(start, start.Add(2*time.Second))
+		require.NoError(t, err)
+		iv.StartTime, iv.StopTime = start, start.Add(2*time.Second)
+
+		mon.printProgress(iv, 20*time.Second)
+
+		assert.Equal(t, "[select] elapsed: 20s remaining: 40s threads: 1/2 qps: 5.50 p50: 1ms p99.9: 1ms errors: 1\n", out.String())
+	})
+
+	t.Run("should print iterations for iteration scenario", func(t *testing.T) {
+		threads := newMonitorThreads(t, 2)
+		mon, err := NewMonitor(&logger, runCfg, &ScenarioConfig{Name: "insert", Iterations: 100}, threads)
+		require.NoError(t, err)
+		var out bytes.Buffer
+		mon.progressOut = &out
+
+		threads[0].Metric.AddIter()
+		start := time.Now()
+		iv, err := mon.collect(start, start.Add(time.Second))
+		require.NoError(t, err)
+
+		mon.printProgress(iv, time.Second)
+
+		assert.Contains(t, out.String(), "[insert] elapsed: 1s iterations: 1/200 threads: 0/2")
+	})
+
+	t.Run("should print connection pool usage", func(t *testing.T) {
+		threads := newMonitorThreads(t, 1)
+		mon, err := NewMonitor(&logger, runCfg, &ScenarioConfig{Name: "select", Duration: time.Minute}, threads)
+		require.NoError(t, err)
+		var out bytes.Buffer
+		mon.progressOut = &out
+		stats := sql.DBStats{OpenConnections: 2, InUse: 1, WaitCount: 3}
+		mon.SetPoolSampler(NewPoolSampler(func() sql.DBStats { return stats }, time.Second))
+
+		stats.InUse, stats.WaitCount, stats.WaitDuration = 2, 8, 40*time.Millisecond
+		start := time.Now()
+		iv, err := mon.collect(start, start.Add(time.Second))
+		require.NoError(t, err)
+
+		mon.printProgress(iv, time.Second)
+
+		assert.Contains(t, out.String(), " conns in use: 2/2 waits: 5 wait time: 40ms\n")
+	})
+}
+
+func TestMonitor_checkAbort(t *testing.T) {
+	logger := zerolog.Nop()
+
+	t.Run("should abort on error rate within window", func(t *testing.T) {
+		threads := newMonitorThreads(t, 2)
+		cfg := &ScenarioConfig{Name: "broken", ThresholdsConfig: &ThresholdsConfig{Abort: []string{"failed_rate < 20%"}}}
+		mon, err := NewMonitor(&logger, &RunConfig{}, cfg, threads)
+		require.NoError(t, err)
+
+		// Healthy first interval
+		start := time.Now()
+		require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond}))
+		_, err = mon.collect(start, start.Add(time.Second))
+		require.NoError(t, err)
+		assert.Empty(t, mon.checkAbort())
+
+		// Every query fails in the second interval
+		for _, th := range threads {
+			require.NoError(t, th.Metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond, Err: assert.AnError}))
+		}
+		_, err = mon.collect(start.Add(time.Second), start.Add(2*time.Second))
+		require.NoError(t, err)
+
+		reason := mon.checkAbort()
+		assert.Contains(t, reason, `scenario "broken"`)
+		assert.Contains(t, reason, "failed_rate < 20%")
+		assert.Contains(t, reason, "66.67%")
+	})
+
+	t.Run("should forget intervals outside of window", func(t *testing.T) {
+		threads := newMonitorThreads(t, 1)
+		cfg := &ScenarioConfig{ThresholdsConfig: &ThresholdsConfig{Abort: []string{"failed_rate < 20%"}, AbortWindow: 2 * time.Second}}
+		mon, err := NewMonitor(&logger, &RunConfig{}, cfg, threads)
+		require.NoError(t, err)
+
+		start := time.Now()
+		require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{Err: assert.AnError}))
+		for i := 0; i < 4; i++ {
+			require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond}))
+			_, err = mon.collect(start.Add(time.Duration(i)*time.Second), start.Add(time.Duration(i+1)*time.Second))
+			require.NoError(t, err)
+		}
+
+		assert.Len(t, mon.window, 2)
+		assert.Equal(t, int64(5), mon.total.QueriesTotal)
+		assert.Empty(t, mon.checkAbort())
+	})
+
+	t.Run("should abort on total errors", func(t *testing.T) {
+		threads := newMonitorThreads(t, 1)
+		cfg := &ScenarioConfig{ThresholdsConfig: &ThresholdsConfig{Abort: []string{"errors < 3"}, AbortWindow: time.Second}}
+		mon, err := NewMonitor(&logger, &RunConfig{}, cfg, threads)
+		require.NoError(t, err)
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{Err: assert.AnError}))
+			for j := 0; j < 10; j++ {
+				require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{}))
+			}
+			_, err = mon.collect(start.Add(time.Duration(i)*time.Second), start.Add(time.Duration(i+1)*time.Second))
+			require.NoError(t, err)
+		}
+
+		assert.Contains(t, mon.checkAbort(), "errors < 3 (actual: 3)")
+	})
+}
+
+func TestMonitor_Run(t *testing.T) {
+	logger := zerolog.Nop()
+	threads := newMonitorThreads(t, 1)
+	cfg := &ScenarioConfig{ThresholdsConfig: &ThresholdsConfig{Abort: []string{"errors < 1"}}}
+	mon, err := NewMonitor(&logger, &RunConfig{}, cfg, threads)
+	require.NoError(t, err)
+
+	require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{Err: assert.AnError}))
+
+	ctx, abort := context.WithCancelCause(context.Background())
+	defer abort(nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mon.Run(ctx, abort)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * monitorInterval):
+		t.Fatal("monitor did not abort")
+	}
+
+	var abortErr *AbortError
+	require.True(t, errors.As(context.Cause(ctx), &abortErr))
+	assert.Contains(t, abortErr.Reason, "errors < 1")
+}
+
+func TestMonitor_tick_Timeseries(t *testing.T) {
+	logger := zerolog.Nop()
+	threads := newMonitorThreads(t, 2)
+	threads[1].active.Store(true)
+	runCfg := &RunConfig{OutputConfig: &OutputConfig{ReportConfig: &ReportConfig{TimeseriesInterval: 2 * time.Second}}}
+	mon, err := NewMonitor(&logger, runCfg, &ScenarioConfig{Duration: time.Minute}, threads)
+	require.NoError(t, err)
+
+	start := time.Now()
+	mon.bucket.start = start
+	for i := 1; i <= 3; i++ {
+		for j := 0; j < 10; j++ {
+			require.NoError(t, threads[0].Metric.SubmitQueryResult(&QueryResult{RowsAffected: 1, ResponseTime: time.Duration(i) * time.Millisecond}))
+		}
+		require.NoError(t, threads[1].Metric.SubmitQueryResult(&QueryResult{Err: assert.AnError}))
+		assert.True(t, mon.tick(start, start.Add(time.Duration(i-1)*time.Second), start.Add(time.Duration(i)*time.Second), false))
+	}
+
+	// Bucket is recorded only when its period is over
+	require.Len(t, mon.Timeseries, 1)
+	point := mon.Timeseries[0]
+	assert.Equal(t, start, point.Time)
+	assert.Equal(t, int64(22), point.Queries)
+	assert.Equal(t, int64(2), point.Errors)
+	assert.Equal(t, int64(20), point.RowsAffected)
+	assert.InDelta(t, 11.0, point.QPS, 0.01)
+	assert.Equal(t, 1, point.ActiveThreads)
+
+	// Partial bucket is flushed when monitor stops
+	assert.True(t, mon.tick(start, start.Add(3*time.Second), start.Add(3500*time.Millisecond), true))
+	require.Len(t, mon.Timeseries, 2)
+	assert.Equal(t, start.Add(2*time.Second), mon.Timeseries[1].Time)
+	assert.Equal(t, int64(11), mon.Timeseries[1].Queries)
+	require.Len(t, mon.Timeseries[1].Percentiles, len(defaultPercentiles))
+	assert.Equal(t, "p50", mon.Timeseries[1].Percentiles[0].Name)
+	assert.InDelta(t, 3.0, mon.Timeseries[1].Percentiles[0].Value, 0.01)
+}
+
+func TestNewTimeseriesPoint_Empty(t *testing.T) {
+	bucket, err := NewMetric()
+	require.NoError(t, err)
+	start := time.Now()
+	bucket.StartTime, bucket.StopTime = start, start.Add(time.Second)
+
+	point := newTimeseriesPoint(bucket, 0, []float64{50, 99.9})
+
+	assert.Equal(t, 0.0, point.QPS)
+	assert.Equal(t, []*TimeseriesPercentile{{Name: "p50", Value: 0}, {Name: "p99.9", Value: 0}}, point.Percentiles)
+	assert.Nil(t, point.ErrorRates)
+}
+
+func TestNewTimeseriesPoint_ErrorRates(t *testing.T) {
+	bucket, err := NewMetric()
+	require.NoError(t, err)
+	start := time.Now()
+	bucket.StartTime, bucket.StopTime = start, start.Add(2*time.Second)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bucket.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond, Err: context.DeadlineExceeded}))
+	}
+	require.NoError(t, bucket.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond, Err: assert.AnError}))
+
+	point := newTimeseriesPoint(bucket, 1, defaultPercentiles)
+
+	assert.Equal(t, map[string]float64{"timeout": 1.5, "other": 0.5}, point.ErrorRates)
+}
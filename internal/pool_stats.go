@@ -0,0 +1,148 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PoolStats shows how connection pool of database client behaved during the run.
+// Counters cover only the run, connections opened while preparing statements are not counted.
+type PoolStats struct {
+	MaxOpen           int               `json:"max_open_connections"` // 0 means unlimited
+	Open              int               `json:"open_connections"`     // At the end of the run
+	InUse             int               `json:"in_use_connections"`
+	Idle              int               `json:"idle_connections"`
+	PeakOpen          int               `json:"peak_open_connections"`
+	PeakInUse         int               `json:"peak_in_use_connections"`
+	MeanInUse         string            `json:"mean_in_use_connections"`
+	WaitCount         int64             `json:"wait_count"`
+	WaitDuration      string            `json:"wait_duration"`
+	MeanWait          string            `json:"mean_wait_duration"`
+	MaxIdleClosed     int64             `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64             `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64             `json:"max_lifetime_closed"`
+	Timeseries        []*PoolStatsPoint `json:"timeseries"`
+}
+
+// PoolStatsPoint is one sample of connection pool, wait counters are cumulative since run start
+type PoolStatsPoint struct {
+	Time         time.Time `json:"time"`
+	Open         int       `json:"open"`
+	InUse        int       `json:"in_use"`
+	Idle         int       `json:"idle"`
+	WaitCount    int64     `json:"wait_count"`
+	WaitDuration float64   `json:"wait_duration_ms"`
+}
+
+// PoolSampler periodically samples connection pool stats while scenarios run
+type PoolSampler struct {
+	stats    func() sql.DBStats
+	interval time.Duration
+
+	mu       sync.Mutex
+	base     sql.DBStats // Counters at run start
+	last     sql.DBStats
+	points   []*PoolStatsPoint
+	inUseSum int64
+}
+
+func NewPoolSampler(stats func() sql.DBStats, interval time.Duration) *PoolSampler {
+	if interval <= 0 {
+		interval = defaultTimeseriesInterval
+	}
+	return &PoolSampler{
+		stats:    stats,
+		interval: interval,
+		base:     stats(),
+	}
+}
+
+// Run samples pool every interval until ctx is done, the last sample is taken when ctx is done
+func (p *PoolSampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.sample(time.Now())
+			return
+		case now := <-ticker.C:
+			p.sample(now)
+		}
+	}
+}
+
+// Current gets pool stats right now with counters since run start
+func (p *PoolSampler) Current() sql.DBStats {
+	return p.sinceStart(p.stats())
+}
+
+func (p *PoolSampler) sample(now time.Time) {
+	stats := p.Current()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.last = stats
+	p.inUseSum += int64(stats.InUse)
+	p.points = append(p.points, &PoolStatsPoint{
+		Time:         now,
+		Open:         stats.OpenConnections,
+		InUse:        stats.InUse,
+		Idle:         stats.Idle,
+		WaitCount:    stats.WaitCount,
+		WaitDuration: toMillis(stats.WaitDuration),
+	})
+}
+
+func (p *PoolSampler) sinceStart(stats sql.DBStats) sql.DBStats {
+	stats.WaitCount -= p.base.WaitCount
+	stats.WaitDuration -= p.base.WaitDuration
+	stats.MaxIdleClosed -= p.base.MaxIdleClosed
+	stats.MaxIdleTimeClosed -= p.base.MaxIdleTimeClosed
+	stats.MaxLifetimeClosed -= p.base.MaxLifetimeClosed
+	return stats
+}
+
+// Report gets summary of all samples taken
+func (p *PoolSampler) Report() *PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	last := p.last
+	report := &PoolStats{
+		MaxOpen:           last.MaxOpenConnections,
+		Open:              last.OpenConnections,
+		InUse:             last.InUse,
+		Idle:              last.Idle,
+		MeanInUse:         "0.00",
+		WaitCount:         last.WaitCount,
+		WaitDuration:      last.WaitDuration.String(),
+		MeanWait:          time.Duration(0).String(),
+		MaxIdleClosed:     last.MaxIdleClosed,
+		MaxIdleTimeClosed: last.MaxIdleTimeClosed,
+		MaxLifetimeClosed: last.MaxLifetimeClosed,
+		Timeseries:        p.points,
+	}
+	for _, point := range p.points {
+		report.PeakOpen = max(report.PeakOpen, point.Open)
+		report.PeakInUse = max(report.PeakInUse, point.InUse)
+	}
+	if len(p.points) > 0 {
+		report.MeanInUse = fmt.Sprintf("%.2f", float64(p.inUseSum)/float64(len(p.points)))
+	}
+	if last.WaitCount > 0 {
+		report.MeanWait = (last.WaitDuration / time.Duration(last.WaitCount)).String()
+	}
+	return report
+}
@@ -0,0 +1,61 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolSampler_Report(t *testing.T) {
+	stats := sql.DBStats{MaxOpenConnections: 4, OpenConnections: 1, Idle: 1, WaitCount: 10, WaitDuration: time.Second, MaxLifetimeClosed: 1}
+	sampler := NewPoolSampler(func() sql.DBStats { return stats }, time.Second)
+
+	start := time.Now()
+	stats.OpenConnections, stats.InUse, stats.Idle = 4, 4, 0
+	stats.WaitCount, stats.WaitDuration = 14, 1400*time.Millisecond
+	sampler.sample(start)
+	stats.OpenConnections, stats.InUse, stats.Idle = 3, 2, 1
+	stats.MaxLifetimeClosed = 2
+	sampler.sample(start.Add(time.Second))
+
+	report := sampler.Report()
+	assert.Equal(t, 4, report.MaxOpen)
+	assert.Equal(t, 3, report.Open)
+	assert.Equal(t, 2, report.InUse)
+	assert.Equal(t, 1, report.Idle)
+	assert.Equal(t, 4, report.PeakOpen)
+	assert.Equal(t, 4, report.PeakInUse)
+	assert.Equal(t, "3.00", report.MeanInUse)
+	// Waits before run start are not counted
+	assert.Equal(t, int64(4), report.WaitCount)
+	assert.Equal(t, "400ms", report.WaitDuration)
+	assert.Equal(t, "100ms", report.MeanWait)
+	assert.Equal(t, int64(1), report.MaxLifetimeClosed)
+	require.Len(t, report.Timeseries, 2)
+	assert.Equal(t, int64(4), report.Timeseries[0].WaitCount)
+	assert.InDelta(t, 400.0, report.Timeseries[0].WaitDuration, 0.001)
+}
+
+func TestPoolSampler_Run(t *testing.T) {
+	sampler := NewPoolSampler(func() sql.DBStats { return sql.DBStats{OpenConnections: 1, InUse: 1} }, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sampler.Run(ctx)
+
+	report := sampler.Report()
+	require.Len(t, report.Timeseries, 1)
+	assert.Equal(t, 1, report.PeakInUse)
+	assert.Equal(t, "0s", report.MeanWait)
+}
@@ -0,0 +1,262 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultPrometheusPath = "/metrics"
+	prometheusReadTimeout = 5 * time.Second
+)
+
+// Upper bounds of query duration histogram buckets in seconds
+var promBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// QueryObserver receives results of scenario statements right after they are executed
+type QueryObserver interface {
+	ObserveQuery(threadId, statement int, q *QueryResult)
+	ObserveIteration()
+}
+
+// ObserverProvider creates query observer for each scenario of the run
+type ObserverProvider interface {
+	AddScenario(name string, statements []*StatementExecutor, threads []*Thread) QueryObserver
+}
+
+// PromExporter serves live scenario metrics in Prometheus text format
+type PromExporter struct {
+	cfg       *PrometheusConfig
+	logger    *zerolog.Logger
+	server    *http.Server
+	addr      string // Actual listen address, set on Start
+	scenarios []*promScenario
+	poolStats func() sql.DBStats // Nil if pool metrics are not exported
+}
+
+type promScenario struct {
+	name       string
+	threads    []*Thread
+	iterations atomic.Int64
+	statements []*promSeries
+}
+
+// promSeries holds counters of one scenario statement
+type promSeries struct {
+	statement string
+	queries   atomic.Int64
+	rows      atomic.Int64
+	sumNanos  atomic.Int64
+	buckets   []atomic.Int64 // Non-cumulative, last one is +Inf
+
+	mu     sync.Mutex
+	errors map[string]int64 // By error class
+}
+
+func NewPromExporter(cfg *PrometheusConfig, logger *zerolog.Logger) *PromExporter {
+	return &PromExporter{
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// AddScenario registers scenario and returns observer which must receive its query results
+func (e *PromExporter) AddScenario(name string, statements []*StatementExecutor, threads []*Thread) QueryObserver {
+	sc := &promScenario{name: name, threads: threads}
+	for _, stmt := range statements {
+		sc.statements = append(sc.statements, &promSeries{
+			statement: stmt.Name,
+			buckets:   make([]atomic.Int64, len(promBuckets)+1),
+			errors:    make(map[string]int64),
+		})
+	}
+	e.scenarios = append(e.scenarios, sc)
+	return sc
+}
+
+// SetPoolStats makes exporter serve connection pool metrics taken from stats on each scrape
+func (e *PromExporter) SetPoolStats(stats func() sql.DBStats) {
+	e.poolStats = stats
+}
+
+// Start listens on configured address and serves metrics in background
+func (e *PromExporter) Start() error {
+	ln, err := net.Listen("tcp", e.cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to start prometheus listener: %w", err)
+	}
+	path := e.cfg.Path
+	if path == "" {
+		path = defaultPrometheusPath
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := e.write(w); err != nil {
+			e.logger.Error().Err(err).Msg("Failed to write prometheus metrics")
+		}
+	})
+	e.server = &http.Server{Handler: mux, ReadHeaderTimeout: prometheusReadTimeout}
+	e.addr = ln.Addr().String()
+
+	go func() {
+		if err := e.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.logger.Error().Err(err).Msg("Prometheus listener stopped")
+		}
+	}()
+	e.logger.Info().Str("listen", e.addr).Str("path", path).Msg("Prometheus metrics endpoint started")
+	return nil
+}
+
+func (e *PromExporter) Close() error {
+	if e.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), prometheusReadTimeout)
+	defer cancel()
+	return e.server.Shutdown(ctx)
+}
+
+func (sc *promScenario) ObserveQuery(_, statement int, q *QueryResult) {
+	if q == nil || statement >= len(sc.statements) {
+		return
+	}
+	s := sc.statements[statement]
+	s.queries.Add(1)
+	s.rows.Add(q.RowsAffected)
+	s.sumNanos.Add(int64(q.ResponseTime))
+	s.buckets[sort.SearchFloat64s(promBuckets, q.ResponseTime.Seconds())].Add(1)
+	if q.Err != nil {
+		s.mu.Lock()
+		s.errors[errorClass(q.Err)]++
+		s.mu.Unlock()
+	}
+}
+
+func (sc *promScenario) ObserveIteration() {
+	sc.iterations.Add(1)
+}
+
+// Write all metrics in Prometheus text exposition format
+func (e *PromExporter) write(w io.Writer) error {
+	var b strings.Builder
+
+	writePromHeader(&b, "loadhound_queries_total", "counter", "Total number of executed queries.")
+	for _, sc := range e.scenarios {
+		for _, s := range sc.statements {
+			fmt.Fprintf(&b, "loadhound_queries_total{%s} %d\n", promLabels(sc.name, s.statement), s.queries.Load())
+		}
+	}
+
+	writePromHeader(&b, "loadhound_errors_total", "counter", "Total number of failed queries by error class.")
+	for _, sc := range e.scenarios {
+		for _, s := range sc.statements {
+			s.mu.Lock()
+			classes := make([]string, 0, len(s.errors))
+			for class := range s.errors {
+				classes = append(classes, class)
+			}
+			sort.Strings(classes)
+			for _, class := range classes {
+				fmt.Fprintf(&b, "loadhound_errors_total{%s,class=\"%s\"} %d\n", promLabels(sc.name, s.statement), escapeLabel(class), s.errors[class])
+			}
+			s.mu.Unlock()
+		}
+	}
+
+	writePromHeader(&b, "loadhound_rows_affected_total", "counter", "Total number of rows affected or returned by queries.")
+	for _, sc := range e.scenarios {
+		for _, s := range sc.statements {
+			fmt.Fprintf(&b, "loadhound_rows_affected_total{%s} %d\n", promLabels(sc.name, s.statement), s.rows.Load())
+		}
+	}
+
+	writePromHeader(&b, "loadhound_query_duration_seconds", "histogram", "Query response time.")
+	for _, sc := range e.scenarios {
+		for _, s := range sc.statements {
+			labels := promLabels(sc.name, s.statement)
+			var cumulative int64
+			for idx, le := range promBuckets {
+				cumulative += s.buckets[idx].Load()
+				fmt.Fprintf(&b, "loadhound_query_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(le, 'f', -1, 64), cumulative)
+			}
+			cumulative += s.buckets[len(promBuckets)].Load()
+			fmt.Fprintf(&b, "loadhound_query_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, cumulative)
+			fmt.Fprintf(&b, "loadhound_query_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(time.Duration(s.sumNanos.Load()).Seconds(), 'f', -1, 64))
+			fmt.Fprintf(&b, "loadhound_query_duration_seconds_count{%s} %d\n", labels, cumulative)
+		}
+	}
+
+	writePromHeader(&b, "loadhound_iterations_total", "counter", "Total number of completed thread iterations.")
+	for _, sc := range e.scenarios {
+		fmt.Fprintf(&b, "loadhound_iterations_total{scenario=\"%s\"} %d\n", escapeLabel(sc.name), sc.iterations.Load())
+	}
+
+	writePromHeader(&b, "loadhound_active_threads", "gauge", "Number of running threads.")
+	for _, sc := range e.scenarios {
+		active := 0
+		for _, th := range sc.threads {
+			if th.IsActive() {
+				active++
+			}
+		}
+		fmt.Fprintf(&b, "loadhound_active_threads{scenario=\"%s\"} %d\n", escapeLabel(sc.name), active)
+	}
+
+	if e.poolStats != nil {
+		writePoolMetrics(&b, e.poolStats())
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Write connection pool metrics, pool is shared by all scenarios so they have no labels of scenario
+func writePoolMetrics(b *strings.Builder, stats sql.DBStats) {
+	writePromHeader(b, "loadhound_db_connections", "gauge", "Number of database connections by state.")
+	fmt.Fprintf(b, "loadhound_db_connections{state=\"open\"} %d\n", stats.OpenConnections)
+	fmt.Fprintf(b, "loadhound_db_connections{state=\"in_use\"} %d\n", stats.InUse)
+	fmt.Fprintf(b, "loadhound_db_connections{state=\"idle\"} %d\n", stats.Idle)
+
+	writePromHeader(b, "loadhound_db_wait_total", "counter", "Total number of waits for free connection.")
+	fmt.Fprintf(b, "loadhound_db_wait_total %d\n", stats.WaitCount)
+	writePromHeader(b, "loadhound_db_wait_duration_seconds_total", "counter", "Total time spent waiting for free connection.")
+	fmt.Fprintf(b, "loadhound_db_wait_duration_seconds_total %s\n", strconv.FormatFloat(stats.WaitDuration.Seconds(), 'f', -1, 64))
+
+	writePromHeader(b, "loadhound_db_connections_closed_total", "counter", "Total number of connections closed by pool limits.")
+	fmt.Fprintf(b, "loadhound_db_connections_closed_total{reason=\"max_idle\"} %d\n", stats.MaxIdleClosed)
+	fmt.Fprintf(b, "loadhound_db_connections_closed_total{reason=\"max_idle_time\"} %d\n", stats.MaxIdleTimeClosed)
+	fmt.Fprintf(b, "loadhound_db_connections_closed_total{reason=\"max_lifetime\"} %d\n", stats.MaxLifetimeClosed)
+}
+
+func writePromHeader(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func promLabels(scenario, statement string) string {
+	return fmt.Sprintf("scenario=\"%s\",statement=\"%s\"", escapeLabel(scenario), escapeLabel(statement))
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
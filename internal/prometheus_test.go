@@ -0,0 +1,93 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromExporter(t *testing.T) {
+	logger := zerolog.Nop()
+	exporter := NewPromExporter(&PrometheusConfig{Listen: "127.0.0.1:0"}, &logger)
+
+	threads := newMonitorThreads(t, 2)
+	threads[0].active.Store(true)
+	statements := []*StatementExecutor{{Name: "select_user"}, {Name: `update "balance"`}}
+	observer := exporter.AddScenario("checkout", statements, threads)
+
+	observer.ObserveQuery(1, 0, &QueryResult{RowsAffected: 1, ResponseTime: 2 * time.Millisecond})
+	observer.ObserveQuery(1, 0, &QueryResult{RowsAffected: 1, ResponseTime: 20 * time.Second})
+	observer.ObserveQuery(1, 1, &QueryResult{ResponseTime: time.Millisecond, Err: context.DeadlineExceeded})
+	observer.ObserveQuery(1, 1, &QueryResult{ResponseTime: time.Millisecond, Err: assert.AnError})
+	observer.ObserveIteration()
+	exporter.SetPoolStats(func() sql.DBStats {
+		return sql.DBStats{OpenConnections: 4, InUse: 3, Idle: 1, WaitCount: 7, WaitDuration: 1500 * time.Millisecond, MaxLifetimeClosed: 2}
+	})
+
+	require.NoError(t, exporter.Start())
+	defer func() {
+		require.NoError(t, exporter.Close())
+	}()
+
+	resp, err := http.Get("http://" + exporter.addr + defaultPrometheusPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain"))
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	body := string(data)
+
+	expected := []string{
+		"# TYPE loadhound_queries_total counter",
+		`loadhound_queries_total{scenario="checkout",statement="select_user"} 2`,
+		`loadhound_queries_total{scenario="checkout",statement="update \"balance\""} 2`,
+		`loadhound_errors_total{scenario="checkout",statement="update \"balance\"",class="other"} 1`,
+		`loadhound_errors_total{scenario="checkout",statement="update \"balance\"",class="timeout"} 1`,
+		`loadhound_rows_affected_total{scenario="checkout",statement="select_user"} 2`,
+		"# TYPE loadhound_query_duration_seconds histogram",
+		`loadhound_query_duration_seconds_bucket{scenario="checkout",statement="select_user",le="0.001"} 0`,
+		`loadhound_query_duration_seconds_bucket{scenario="checkout",statement="select_user",le="0.0025"} 1`,
+		`loadhound_query_duration_seconds_bucket{scenario="checkout",statement="select_user",le="10"} 1`,
+		`loadhound_query_duration_seconds_bucket{scenario="checkout",statement="select_user",le="+Inf"} 2`,
+		`loadhound_query_duration_seconds_sum{scenario="checkout",statement="select_user"} 20.002`,
+		`loadhound_query_duration_seconds_count{scenario="checkout",statement="select_user"} 2`,
+		`loadhound_iterations_total{scenario="checkout"} 1`,
+		`loadhound_active_threads{scenario="checkout"} 1`,
+		`loadhound_db_connections{state="in_use"} 3`,
+		`loadhound_db_connections{state="idle"} 1`,
+		`loadhound_db_wait_total 7`,
+		`loadhound_db_wait_duration_seconds_total 1.5`,
+		`loadhound_db_connections_closed_total{reason="max_lifetime"} 2`,
+	}
+	for _, line := range expected {
+		assert.Contains(t, body, line+"\n")
+	}
+}
+
+func TestPromExporter_Start_InvalidAddress(t *testing.T) {
+	logger := zerolog.Nop()
+	exporter := NewPromExporter(&PrometheusConfig{Listen: "invalid:address:1"}, &logger)
+
+	err := exporter.Start()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to start prometheus listener")
+	assert.NoError(t, exporter.Close())
+}
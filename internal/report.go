@@ -10,13 +10,21 @@ package internal
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/caio/go-tdigest/v4"
 	"github.com/fatih/color"
 )
 
+// Response time percentiles reported when not configured
+var defaultPercentiles = []float64{50, 90, 95, 99}
+
 type ReportData struct {
 	RunConfig    RunConfig  `json:"test_config"`
 	TestDuration string     `json:"test_duration"`
@@ -45,10 +53,13 @@ type ThreadData struct {
 }
 
 func GenerateReport(cfg *RunConfig, scenariosMetrics []*Metric) error {
+	// Reports are calculated even without output, thresholds depend on them
+	if err := calculateReports(cfg, scenariosMetrics); err != nil {
+		return err
+	}
 	if cfg.OutputConfig == nil || cfg.OutputConfig.ReportConfig == nil {
 		return nil
 	}
-	calculateReports(cfg, scenariosMetrics)
 	reportCfg := cfg.OutputConfig.ReportConfig
 	if reportCfg.ToConsole {
 		printColorReport(cfg)
@@ -74,33 +85,130 @@ func GenerateReport(cfg *RunConfig, scenariosMetrics []*Metric) error {
 			return err
 		}
 	}
+
+	if reportCfg.ToHTML {
+		if err := writeHTMLReport(cfg, scenariosMetrics); err != nil {
+			return err
+		}
+	}
+
+	if reportCfg.ToJUnit {
+		if err := writeJUnitReport(cfg); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func calculateReports(cfg *RunConfig, scenariosMetrics []*Metric) {
+func calculateReports(cfg *RunConfig, scenariosMetrics []*Metric) error {
 	scenariosCfg := cfg.WorkflowConfig.Scenarios
+	percentiles := reportPercentiles(cfg)
 	for idx, sc := range scenariosMetrics {
-		// Get percentiles
-		respMin, respMax := sc.Td.Quantile(0.00), sc.Td.Quantile(1)
-		p50, p90, p95 := sc.Td.Quantile(0.50), sc.Td.Quantile(0.90), sc.Td.Quantile(0.95)
-
+		mean, stdDev := meanStdDev(sc.Td)
+		errs := getErrorReports(sc.ErrMap, sc.ErrSamples)
 		scenariosCfg[idx].Report = &Report{
 			Duration:          sc.StopTime.Sub(sc.StartTime).String(),
 			ThreadsTotal:      sc.ThreadsTotal,
 			IterationsTotal:   sc.IterationsTotal,
 			QueriesTotal:      sc.QueriesTotal,
 			QPS:               fmt.Sprintf("%.2f", sc.GetQPS()),
-			RespMin:           time.Duration(respMin).String(),
-			RespMax:           time.Duration(respMax).String(),
+			RespMin:           quantile(sc.Td, 0).String(),
+			RespMax:           quantile(sc.Td, 1).String(),
+			MeanResp:          mean.String(),
+			StdDevResp:        stdDev.String(),
 			SuccessRate:       fmt.Sprintf("%.2f%%", sc.GetSuccessRate()),
 			FailedRate:        fmt.Sprintf("%.2f%%", sc.GetFailedRate()),
-			P50:               time.Duration(p50).String(),
-			P90:               time.Duration(p90).String(),
-			P95:               time.Duration(p95).String(),
+			Percentiles:       getPercentiles(sc.Td, percentiles),
 			RowsAffectedTotal: sc.RowsAffected,
 			ErrCount:          sc.ErrorsTotal,
-			TopErrors:         getTopErrors(sc.ErrMap),
+			SlowTotal:         sc.SlowTotal,
+			Errors:            errs,
+			TopErrors:         getTopErrors(errs),
+			SerializationErrs: sc.SerializationFailuresTotal,
+			DeadlockErrs:      sc.DeadlocksTotal,
+			DroppedArrivals:   sc.DroppedTotal,
+			DelayedArrivals:   sc.DelayedTotal,
+			Aborted:           sc.AbortReason != "",
+			AbortReason:       sc.AbortReason,
+			Interrupted:       sc.Interrupted,
+			Timeseries:        sc.Timeseries,
+		}
+		if sc.QueueTd.Count() > 0 {
+			scenariosCfg[idx].Report.QueueDelayMax = quantile(sc.QueueTd, 1).String()
+			scenariosCfg[idx].Report.QueueDelayPercentiles = getPercentiles(sc.QueueTd, percentiles)
+		}
+		if sc.Hdr != nil {
+			calculateHdrReport(scenariosCfg[idx].Report, sc, percentiles)
+		}
+		if len(sc.Statements) > 0 {
+			calculateStatementsReport(scenariosCfg[idx], sc, percentiles)
+		}
+
+		thresholds, err := evaluateThresholds(cfg.ThresholdsConfig, scenariosCfg[idx], sc)
+		if err != nil {
+			return err
 		}
+		scenariosCfg[idx].Report.Thresholds = thresholds
+	}
+	return nil
+}
+
+// Replace response time stats with HDR histogram ones and add stats corrected for coordinated omission
+func calculateHdrReport(report *Report, sc *Metric, percentiles []float64) {
+	report.RespMin = hdrDuration(sc.Hdr.Min()).String()
+	report.RespMax = hdrDuration(sc.Hdr.Max()).String()
+	report.MeanResp = hdrDuration(int64(sc.Hdr.Mean())).String()
+	report.StdDevResp = hdrDuration(int64(sc.Hdr.StdDev())).String()
+	report.Percentiles = getHdrPercentiles(sc.Hdr, percentiles)
+
+	report.CorrectedMax = hdrDuration(sc.CorrectedHdr.Max()).String()
+	report.CorrectedMean = hdrDuration(int64(sc.CorrectedHdr.Mean())).String()
+	report.CorrectedPercentiles = getHdrPercentiles(sc.CorrectedHdr, percentiles)
+}
+
+func getHdrPercentiles(h *hdrhistogram.Histogram, percentiles []float64) []*Percentile {
+	res := make([]*Percentile, 0, len(percentiles))
+	for _, p := range percentiles {
+		res = append(res, &Percentile{Name: percentileName(p), Value: hdrDuration(h.ValueAtPercentile(p)).String()})
+	}
+	return res
+}
+
+// Convert HDR histogram value in microseconds to duration
+func hdrDuration(v int64) time.Duration {
+	return time.Duration(v) * time.Microsecond
+}
+
+// Calculate iteration latency and per statement results for multi-statement scenario
+func calculateStatementsReport(cfg *ScenarioConfig, sc *Metric, percentiles []float64) {
+	report := cfg.Report
+	report.IterPercentiles = getPercentiles(sc.IterTd, percentiles)
+
+	statements := cfg.GetStatements()
+	for idx, stmt := range sc.Statements {
+		mean, stdDev := meanStdDev(stmt.Td)
+		stmtReport := &StatementReport{
+			Name:              statementName(statements[idx], idx),
+			QueriesTotal:      stmt.QueriesTotal,
+			RespMin:           quantile(stmt.Td, 0).String(),
+			RespMax:           quantile(stmt.Td, 1).String(),
+			MeanResp:          mean.String(),
+			StdDevResp:        stdDev.String(),
+			FailedRate:        fmt.Sprintf("%.2f%%", stmt.GetFailedRate()),
+			Percentiles:       getPercentiles(stmt.Td, percentiles),
+			RowsAffectedTotal: stmt.RowsAffected,
+			ErrCount:          stmt.ErrorsTotal,
+			SlowTotal:         stmt.SlowTotal,
+		}
+		// Statements report measured response time only, corrected one is reported for scenario
+		if stmt.Hdr != nil {
+			stmtReport.RespMin = hdrDuration(stmt.Hdr.Min()).String()
+			stmtReport.RespMax = hdrDuration(stmt.Hdr.Max()).String()
+			stmtReport.MeanResp = hdrDuration(int64(stmt.Hdr.Mean())).String()
+			stmtReport.StdDevResp = hdrDuration(int64(stmt.Hdr.StdDev())).String()
+			stmtReport.Percentiles = getHdrPercentiles(stmt.Hdr, percentiles)
+		}
+		report.Statements = append(report.Statements, stmtReport)
 	}
 }
 
@@ -109,6 +217,8 @@ func printColorReport(cfg *RunConfig) {
 
 	bold := color.New(color.Bold).SprintFunc()
 	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
 
 	fmt.Print(bold("\n========== LoadHound Report ==========\n"))
@@ -116,6 +226,12 @@ func printColorReport(cfg *RunConfig) {
 		report := sc.Report
 		fmt.Println()
 		fmt.Println(bold(fmt.Sprintf("Name: %s", sc.Name)))
+		if report.Aborted {
+			fmt.Println(red(fmt.Sprintf("aborted: %s", report.AbortReason)))
+		}
+		if report.Interrupted {
+			fmt.Println(yellow("interrupted: partial results, test was stopped by signal"))
+		}
 
 		fmt.Printf("duration: %s\n", cyan(report.Duration))
 
@@ -128,61 +244,187 @@ func printColorReport(cfg *RunConfig) {
 			cyan(report.QPS),
 			cyan(report.RowsAffectedTotal))
 
-		fmt.Printf("response time - min: %s  max: %s\n",
+		fmt.Printf("response time - min: %s  max: %s  mean: %s  stddev: %s\n",
 			cyan(report.RespMin),
-			cyan(report.RespMax))
-		fmt.Printf("response time - p50: %s  p90: %s  p95: %s\n",
-			cyan(report.P50),
-			cyan(report.P90),
-			cyan(report.P95))
+			cyan(report.RespMax),
+			cyan(report.MeanResp),
+			cyan(report.StdDevResp))
+		fmt.Printf("response time - %s\n", formatPercentiles(report.Percentiles, cyan))
+		if len(report.CorrectedPercentiles) > 0 {
+			fmt.Printf("corrected response time - max: %s  mean: %s\n",
+				cyan(report.CorrectedMax),
+				cyan(report.CorrectedMean))
+			fmt.Printf("corrected response time - %s\n", formatPercentiles(report.CorrectedPercentiles, cyan))
+		}
 		fmt.Println()
 
+		if len(report.Statements) > 0 {
+			fmt.Println(bold("Statements"))
+			fmt.Printf("iteration time - %s\n", formatPercentiles(report.IterPercentiles, cyan))
+			for _, stmt := range report.Statements {
+				fmt.Printf("%s: queries total: %s failed_rate: %s affected rows: %s\n",
+					bold(stmt.Name),
+					cyan(stmt.QueriesTotal),
+					cyan(stmt.FailedRate),
+					cyan(stmt.RowsAffectedTotal))
+				fmt.Printf("  response time - min: %s  max: %s  mean: %s  stddev: %s\n",
+					cyan(stmt.RespMin),
+					cyan(stmt.RespMax),
+					cyan(stmt.MeanResp),
+					cyan(stmt.StdDevResp))
+				fmt.Printf("  response time - %s\n", formatPercentiles(stmt.Percentiles, cyan))
+			}
+			fmt.Println()
+		}
+
 		fmt.Println(bold("Thread"))
 		fmt.Printf("thread count: %s\n", cyan(report.ThreadsTotal))
 		fmt.Printf("iteration count: %s\n", cyan(report.IterationsTotal))
+		if sc.Rate > 0 {
+			fmt.Printf("target rate: %s dropped arrivals: %s delayed arrivals: %s\n",
+				cyan(sc.Rate),
+				cyan(report.DroppedArrivals),
+				cyan(report.DelayedArrivals))
+			if len(report.QueueDelayPercentiles) > 0 {
+				fmt.Printf("queue delay - %s  max: %s\n", formatPercentiles(report.QueueDelayPercentiles, cyan), cyan(report.QueueDelayMax))
+			}
+		}
 		fmt.Println()
 
 		fmt.Println(bold("Errors"))
 		fmt.Printf("errors count: %s\n", cyan(report.ErrCount))
-		if len(report.TopErrors) == 0 {
+		if report.SlowTotal > 0 {
+			fmt.Printf("slow queries: %s\n", cyan(report.SlowTotal))
+		}
+		if sc.TxConfig != nil {
+			fmt.Printf("serialization failures: %s deadlocks: %s\n",
+				cyan(report.SerializationErrs),
+				cyan(report.DeadlockErrs))
+		}
+		if len(report.Errors) == 0 {
 			fmt.Println(green("No errors recorded."))
 		} else {
-			for idx, err := range report.TopErrors {
-				fmt.Printf("%d. %s\n", idx+1, err)
+			for idx, e := range report.Errors {
+				fmt.Printf("%d. %s: %s (%s)\n", idx+1, e.Class, cyan(e.Count), strings.Join(e.Samples, "; "))
+			}
+		}
+
+		if len(report.Thresholds) > 0 {
+			fmt.Println()
+			fmt.Println(bold("Thresholds"))
+			for _, res := range report.Thresholds {
+				status := green("PASS")
+				if !res.Passed {
+					status = red("FAIL")
+				}
+				fmt.Printf("%s %s (actual: %s)\n", status, res.Expr, cyan(res.Actual))
 			}
 		}
 	}
-}
 
-type errKV struct {
-	key   string
-	value int64
+	if pool := cfg.PoolStats; pool != nil {
+		fmt.Println()
+		fmt.Println(bold("Connection pool"))
+		maxOpen := "unlimited"
+		if pool.MaxOpen > 0 {
+			maxOpen = strconv.Itoa(pool.MaxOpen)
+		}
+		fmt.Printf("max open: %s peak open: %s peak in use: %s mean in use: %s\n",
+			cyan(maxOpen),
+			cyan(pool.PeakOpen),
+			cyan(pool.PeakInUse),
+			cyan(pool.MeanInUse))
+		fmt.Printf("at the end - open: %s in use: %s idle: %s\n",
+			cyan(pool.Open),
+			cyan(pool.InUse),
+			cyan(pool.Idle))
+		waits := fmt.Sprintf("waits for connection: %d total wait time: %s mean wait: %s", pool.WaitCount, pool.WaitDuration, pool.MeanWait)
+		if pool.WaitCount > 0 {
+			fmt.Println(yellow(waits))
+		} else {
+			fmt.Println(green(waits))
+		}
+		fmt.Printf("closed by max idle: %s max idle time: %s max lifetime: %s\n",
+			cyan(pool.MaxIdleClosed),
+			cyan(pool.MaxIdleTimeClosed),
+			cyan(pool.MaxLifetimeClosed))
+	}
 }
 
-// Get top 5 errors by count
-func getTopErrors(errMap map[string]int64) []string {
-	const maxErrLen = 5
+// Get configured report percentiles or default ones
+func reportPercentiles(cfg *RunConfig) []float64 {
+	if cfg.OutputConfig != nil && cfg.OutputConfig.ReportConfig != nil && len(cfg.OutputConfig.ReportConfig.Percentiles) > 0 {
+		return cfg.OutputConfig.ReportConfig.Percentiles
+	}
+	return defaultPercentiles
+}
 
-	// Mapping to slice for sorting
-	errKVs := make([]errKV, 0, len(errMap))
-	for k, v := range errMap {
-		errKVs = append(errKVs, errKV{k, v})
+// Get response times at percentiles, named the same way as threshold metrics: p50, p99.9
+func getPercentiles(td *tdigest.TDigest, percentiles []float64) []*Percentile {
+	res := make([]*Percentile, 0, len(percentiles))
+	for _, p := range percentiles {
+		res = append(res, &Percentile{Name: percentileName(p), Value: quantile(td, p/100).String()})
 	}
+	return res
+}
 
-	// Sorting by desc
-	sort.Slice(errKVs, func(i, j int) bool {
-		return errKVs[i].value > errKVs[j].value
+func percentileName(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// Get mean and standard deviation of response time from t-digest centroids
+func meanStdDev(td *tdigest.TDigest) (time.Duration, time.Duration) {
+	var count, sum, sumSq float64
+	td.ForEachCentroid(func(mean float64, weight uint64) bool {
+		w := float64(weight)
+		count += w
+		sum += mean * w
+		sumSq += mean * mean * w
+		return true
 	})
+	if count == 0 {
+		return 0, 0
+	}
+	mean := sum / count
+	variance := math.Max(sumSq/count-mean*mean, 0)
+	return time.Duration(mean), time.Duration(math.Sqrt(variance))
+}
 
-	n := len(errKVs)
-	if n > maxErrLen {
-		n = maxErrLen
+func formatPercentiles(percentiles []*Percentile, colorize func(a ...interface{}) string) string {
+	parts := make([]string, 0, len(percentiles))
+	for _, p := range percentiles {
+		parts = append(parts, fmt.Sprintf("%s: %s", p.Name, colorize(p.Value)))
 	}
+	return strings.Join(parts, "  ")
+}
 
-	errSlice := make([]string, 0, n)
-	for i := 0; i < n; i++ {
-		errSlice = append(errSlice, errKVs[i].key)
+// Get errors by class sorted by count desc
+func getErrorReports(errMap map[string]int64, samples map[string][]string) []*ErrorReport {
+	res := make([]*ErrorReport, 0, len(errMap))
+	for class, count := range errMap {
+		res = append(res, &ErrorReport{Class: class, Count: count, Samples: samples[class]})
 	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count == res[j].Count {
+			return res[i].Class < res[j].Class
+		}
+		return res[i].Count > res[j].Count
+	})
+	return res
+}
+
+// Get first samples of the most frequent error classes, errs are sorted by count desc
+func getTopErrors(errs []*ErrorReport) []string {
+	const maxErrLen = 5
 
-	return errSlice
+	res := make([]string, 0, maxErrLen)
+	for _, e := range errs {
+		if len(res) == maxErrLen {
+			break
+		}
+		if len(e.Samples) > 0 {
+			res = append(res, e.Samples[0])
+		}
+	}
+	return res
 }
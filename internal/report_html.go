@@ -0,0 +1,321 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	chartWidth     = 720
+	chartHeight    = 240
+	chartPadLeft   = 64
+	chartPadRight  = 16
+	chartPadTop    = 28
+	chartPadBottom = 28
+
+	histogramBins   = 40
+	maxChartErrors  = 5
+	chartGridLines  = 4
+	chartEmptyLabel = "not enough data"
+)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+var htmlReportTmpl = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// Password in key=value (postgres) and user:password@ (mysql) DSN formats
+var (
+	dsnPasswordKVRe   = regexp.MustCompile(`(password=)(\S+)`)
+	dsnPasswordUserRe = regexp.MustCompile(`^([^:@/]+):([^@]*)@`)
+)
+
+type htmlReport struct {
+	GeneratedAt string
+	Config      string
+	Scenarios   []*htmlScenario
+	Pool        *PoolStats // Nil if pool wasn't sampled
+}
+
+type htmlScenario struct {
+	Name       string
+	Report     *Report
+	Throughput template.HTML
+	Latency    template.HTML
+	Histogram  template.HTML
+	Errors     template.HTML // Empty if there are no errors
+}
+
+type chartSeries struct {
+	Name   string
+	Color  string
+	Values []float64
+}
+
+// Write self-contained HTML report with charts, it doesn't load anything from network
+func writeHTMLReport(cfg *RunConfig, scenariosMetrics []*Metric) error {
+	filename := fmt.Sprintf("loadhound_report_%s.html", time.Now().Format(time.RFC3339))
+	// #nosec G304 -- filename is generated internally, not from user input
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := renderHTMLReport(f, cfg, scenariosMetrics); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func renderHTMLReport(w io.Writer, cfg *RunConfig, scenariosMetrics []*Metric) error {
+	config, err := effectiveConfig(cfg)
+	if err != nil {
+		return err
+	}
+	data := &htmlReport{
+		GeneratedAt: time.Now().Format(time.RFC1123),
+		Config:      config,
+		Pool:        cfg.PoolStats,
+	}
+	for idx, m := range scenariosMetrics {
+		sc := cfg.WorkflowConfig.Scenarios[idx]
+		data.Scenarios = append(data.Scenarios, &htmlScenario{
+			Name:       sc.Name,
+			Report:     sc.Report,
+			Throughput: throughputChart(sc.Report.Timeseries),
+			Latency:    latencyChart(sc.Report.Timeseries),
+			Histogram:  histogramChart(m),
+			Errors:     errorsChart(sc.Report.Timeseries, sc.Report.Errors),
+		})
+	}
+	return htmlReportTmpl.Execute(w, data)
+}
+
+// Get configuration as indented JSON without results and with password removed from DSN
+func effectiveConfig(cfg *RunConfig) (string, error) {
+	cfgCopy := *cfg
+	cfgCopy.PoolStats = nil
+	if cfg.DbConfig != nil {
+		dbCopy := *cfg.DbConfig
+		dbCopy.Dsn = redactDsn(dbCopy.Dsn)
+		cfgCopy.DbConfig = &dbCopy
+	}
+	if cfg.WorkflowConfig != nil {
+		scenarios := make([]*ScenarioConfig, 0, len(cfg.WorkflowConfig.Scenarios))
+		for _, sc := range cfg.WorkflowConfig.Scenarios {
+			scCopy := *sc
+			scCopy.Report = nil
+			scenarios = append(scenarios, &scCopy)
+		}
+		cfgCopy.WorkflowConfig = &WorkflowConfig{Scenarios: scenarios}
+	}
+	data, err := json.MarshalIndent(&cfgCopy, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func redactDsn(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.User != nil {
+		return u.Redacted()
+	}
+	dsn = dsnPasswordKVRe.ReplaceAllString(dsn, "${1}xxxxx")
+	return dsnPasswordUserRe.ReplaceAllString(dsn, "${1}:xxxxx@")
+}
+
+func throughputChart(ts []*TimeseriesPoint) template.HTML {
+	qps := make([]float64, len(ts))
+	for idx, p := range ts {
+		qps[idx] = p.QPS
+	}
+	return lineChart("Throughput (qps)", ts, []chartSeries{{Name: "qps", Color: "#2b6cb0", Values: qps}}, formatNumber)
+}
+
+// Render configured percentiles and max, all points have the same percentiles
+func latencyChart(ts []*TimeseriesPoint) template.HTML {
+	colors := []string{"#38a169", "#d69e2e", "#dd6b20", "#c53030", "#805ad5", "#2b6cb0"}
+	var series []chartSeries
+	if len(ts) > 0 {
+		for idx, p := range ts[0].Percentiles {
+			values := make([]float64, len(ts))
+			for i, point := range ts {
+				if idx < len(point.Percentiles) {
+					values[i] = point.Percentiles[idx].Value
+				}
+			}
+			series = append(series, chartSeries{Name: p.Name, Color: colors[idx%len(colors)], Values: values})
+		}
+	}
+	maxs := make([]float64, len(ts))
+	for idx, p := range ts {
+		maxs[idx] = p.Max
+	}
+	series = append(series, chartSeries{Name: "max", Color: "#718096", Values: maxs})
+	return lineChart("Response time percentiles (ms)", ts, series, formatNumber)
+}
+
+// Render error rates of the most frequent error classes, errors are sorted by count desc
+func errorsChart(ts []*TimeseriesPoint, errs []*ErrorReport) template.HTML {
+	if len(errs) == 0 {
+		return ""
+	}
+	colors := []string{"#c53030", "#dd6b20", "#d69e2e", "#805ad5", "#718096"}
+	series := make([]chartSeries, 0, maxChartErrors)
+	for idx, e := range errs {
+		if idx == maxChartErrors {
+			break
+		}
+		values := make([]float64, len(ts))
+		for i, p := range ts {
+			values[i] = p.ErrorRates[e.Class]
+		}
+		series = append(series, chartSeries{Name: e.Class, Color: colors[idx], Values: values})
+	}
+	return lineChart("Errors by class (per second)", ts, series, formatNumber)
+}
+
+// Render SVG line chart of time-series values
+func lineChart(title string, ts []*TimeseriesPoint, series []chartSeries, format func(float64) string) template.HTML {
+	var b strings.Builder
+	writeChartHeader(&b, title)
+	if len(ts) < 2 {
+		writeChartEmpty(&b)
+		return template.HTML(b.String()) // #nosec G203 -- SVG is generated internally
+	}
+
+	start := ts[0].Time
+	span := ts[len(ts)-1].Time.Sub(start).Seconds()
+	yMax := 0.0
+	for _, s := range series {
+		for _, v := range s.Values {
+			yMax = math.Max(yMax, v)
+		}
+	}
+	yMax = niceCeil(yMax)
+	writeChartGrid(&b, yMax, format)
+
+	// Legend is aligned to the right, each entry is as wide as its name
+	legendX := make([]int, len(series))
+	x := chartWidth - chartPadRight
+	for idx := len(series) - 1; idx >= 0; idx-- {
+		x -= 7*len(series[idx].Name) + 16
+		legendX[idx] = x
+	}
+
+	plotW, plotH := float64(chartWidth-chartPadLeft-chartPadRight), float64(chartHeight-chartPadTop-chartPadBottom)
+	for idx, s := range series {
+		points := make([]string, 0, len(s.Values))
+		for i, v := range s.Values {
+			x := chartPadLeft + ts[i].Time.Sub(start).Seconds()/span*plotW
+			y := chartPadTop + plotH - v/yMax*plotH
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`, s.Color, strings.Join(points, " "))
+		fmt.Fprintf(&b, `<text x="%d" y="16" fill="%s" class="legend">%s</text>`, legendX[idx], s.Color, template.HTMLEscapeString(s.Name))
+	}
+
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis">0s</text>`, chartPadLeft, chartHeight-8)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis" text-anchor="end">%s</text>`, chartWidth-chartPadRight, chartHeight-8, time.Duration(span*float64(time.Second)).Round(time.Second))
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String()) // #nosec G203 -- SVG is generated internally
+}
+
+// Render SVG histogram of response times, bins are estimated from scenario digest
+func histogramChart(m *Metric) template.HTML {
+	var b strings.Builder
+	writeChartHeader(&b, "Response time distribution")
+
+	total := float64(m.Td.Count())
+	lo, hi := m.Td.Quantile(0), m.Td.Quantile(1)
+	if total == 0 || math.IsNaN(lo) || hi <= lo {
+		writeChartEmpty(&b)
+		return template.HTML(b.String()) // #nosec G203 -- SVG is generated internally
+	}
+
+	width := (hi - lo) / histogramBins
+	counts := make([]float64, histogramBins)
+	prev := 0.0
+	yMax := 0.0
+	for i := range counts {
+		cdf := 1.0
+		if i < histogramBins-1 {
+			cdf = m.Td.CDF(lo + float64(i+1)*width)
+		}
+		counts[i] = math.Max(cdf-prev, 0) * total
+		prev = cdf
+		yMax = math.Max(yMax, counts[i])
+	}
+	yMax = niceCeil(yMax)
+	writeChartGrid(&b, yMax, formatNumber)
+
+	plotW, plotH := float64(chartWidth-chartPadLeft-chartPadRight), float64(chartHeight-chartPadTop-chartPadBottom)
+	barW := plotW / histogramBins
+	for i, c := range counts {
+		h := c / yMax * plotH
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#2b6cb0"><title>%s - %s: %.0f</title></rect>`,
+			chartPadLeft+float64(i)*barW+1, chartPadTop+plotH-h, math.Max(barW-2, 1), h,
+			time.Duration(lo+float64(i)*width).Round(time.Microsecond), time.Duration(lo+float64(i+1)*width).Round(time.Microsecond), c)
+	}
+
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis">%s</text>`, chartPadLeft, chartHeight-8, time.Duration(lo).Round(time.Microsecond))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis" text-anchor="end">%s</text>`, chartWidth-chartPadRight, chartHeight-8, time.Duration(hi).Round(time.Microsecond))
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String()) // #nosec G203 -- SVG is generated internally
+}
+
+func writeChartHeader(b *strings.Builder, title string) {
+	fmt.Fprintf(b, `<svg viewBox="0 0 %d %d" class="chart" role="img">`, chartWidth, chartHeight)
+	fmt.Fprintf(b, `<text x="%d" y="16" class="title">%s</text>`, chartPadLeft, template.HTMLEscapeString(title))
+}
+
+func writeChartEmpty(b *strings.Builder) {
+	fmt.Fprintf(b, `<text x="%d" y="%d" class="axis" text-anchor="middle">%s</text></svg>`, chartWidth/2, chartHeight/2, chartEmptyLabel)
+}
+
+// Draw horizontal grid lines with value labels from 0 to yMax
+func writeChartGrid(b *strings.Builder, yMax float64, format func(float64) string) {
+	plotH := float64(chartHeight - chartPadTop - chartPadBottom)
+	for i := 0; i <= chartGridLines; i++ {
+		y := chartPadTop + plotH - float64(i)/chartGridLines*plotH
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="grid"/>`, chartPadLeft, y, chartWidth-chartPadRight, y)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" class="axis" text-anchor="end">%s</text>`, chartPadLeft-6, y+4, format(yMax*float64(i)/chartGridLines))
+	}
+}
+
+// Round value up to 1, 2 or 5 multiplied by power of ten
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if v <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func formatNumber(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
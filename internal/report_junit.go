@@ -0,0 +1,138 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Time       string           `xml:"time,attr"`
+	Properties []*junitProperty `xml:"properties>property,omitempty"`
+	Cases      []*junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Write JUnit XML report, each scenario is a test suite with a test case per threshold and completion case
+func writeJUnitReport(cfg *RunConfig) error {
+	filename := fmt.Sprintf("loadhound_report_%s.xml", time.Now().Format(time.RFC3339))
+	// #nosec G304 -- filename is generated internally, not from user input
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := renderJUnitReport(f, cfg); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func renderJUnitReport(w io.Writer, cfg *RunConfig) error {
+	suites := &junitTestSuites{Name: "LoadHound"}
+	for _, sc := range cfg.WorkflowConfig.Scenarios {
+		suite := newJUnitTestSuite(sc)
+		suites.Suites = append(suites.Suites, suite)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newJUnitTestSuite(sc *ScenarioConfig) *junitTestSuite {
+	report := sc.Report
+	suite := &junitTestSuite{
+		Name: sc.Name,
+		Properties: []*junitProperty{
+			{Name: "queries_total", Value: fmt.Sprint(report.QueriesTotal)},
+			{Name: "qps", Value: report.QPS},
+			{Name: "mean_resp_time", Value: report.MeanResp},
+			{Name: "failed_rate", Value: report.FailedRate},
+			{Name: "interrupted", Value: fmt.Sprint(report.Interrupted)},
+		},
+	}
+	for _, p := range report.Percentiles {
+		suite.Properties = append(suite.Properties, &junitProperty{Name: p.Name + "_resp_time", Value: p.Value})
+	}
+	if d, err := time.ParseDuration(report.Duration); err == nil {
+		suite.Time = fmt.Sprintf("%.3f", d.Seconds())
+	}
+
+	for _, res := range report.Thresholds {
+		tc := &junitTestCase{Name: res.Expr, ClassName: sc.Name}
+		if !res.Passed {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("expected %s, actual %s", res.Expr, res.Actual),
+				Type:    "threshold",
+				Text:    fmt.Sprintf("threshold: %s\nactual: %s", res.Expr, res.Actual),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	// Every scenario has completion case, so suite without thresholds is not empty.
+	// Aborted run fails it.
+	completed := &junitTestCase{Name: "completed", ClassName: sc.Name}
+	if report.Aborted {
+		completed.Failure = &junitFailure{
+			Message: report.AbortReason,
+			Type:    "abort",
+			Text:    report.AbortReason,
+		}
+	}
+	suite.Cases = append(suite.Cases, completed)
+
+	suite.Tests = len(suite.Cases)
+	for _, tc := range suite.Cases {
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+	}
+	return suite
+}
@@ -0,0 +1,83 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderJUnitReport(t *testing.T) {
+	cfg := &RunConfig{
+		WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{
+			{
+				Name: "select",
+				Report: &Report{
+					Duration: "1m0.5s",
+					QPS:      "1000.00",
+					Thresholds: []*ThresholdResult{
+						{Expr: "p95 < 50ms", Actual: "63ms", Passed: false},
+						{Expr: "failed_rate < 1%", Actual: "0.00%", Passed: true},
+					},
+				},
+			},
+			{
+				Name: "insert",
+				Report: &Report{
+					Duration:    "10s",
+					Aborted:     true,
+					AbortReason: `scenario "insert": errors < 10 (actual: 10)`,
+				},
+			},
+			{
+				Name:   "update",
+				Report: &Report{Duration: "10s"},
+			},
+		}},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, renderJUnitReport(&out, cfg))
+	assert.Contains(t, out.String(), xml.Header)
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &suites))
+
+	assert.Equal(t, 5, suites.Tests)
+	assert.Equal(t, 2, suites.Failures)
+	require.Len(t, suites.Suites, 3)
+
+	selectSuite := suites.Suites[0]
+	assert.Equal(t, "select", selectSuite.Name)
+	assert.Equal(t, "60.500", selectSuite.Time)
+	assert.Equal(t, 3, selectSuite.Tests)
+	assert.Equal(t, 1, selectSuite.Failures)
+	require.Len(t, selectSuite.Cases, 3)
+	assert.Equal(t, "p95 < 50ms", selectSuite.Cases[0].Name)
+	require.NotNil(t, selectSuite.Cases[0].Failure)
+	assert.Equal(t, "expected p95 < 50ms, actual 63ms", selectSuite.Cases[0].Failure.Message)
+	assert.Nil(t, selectSuite.Cases[1].Failure)
+	assert.Equal(t, "completed", selectSuite.Cases[2].Name)
+	assert.Nil(t, selectSuite.Cases[2].Failure)
+
+	insertSuite := suites.Suites[1]
+	require.Len(t, insertSuite.Cases, 1)
+	assert.Equal(t, "completed", insertSuite.Cases[0].Name)
+	require.NotNil(t, insertSuite.Cases[0].Failure)
+	assert.Equal(t, "abort", insertSuite.Cases[0].Failure.Type)
+
+	// Scenario without thresholds is not reported as empty suite
+	updateSuite := suites.Suites[2]
+	assert.Equal(t, 1, updateSuite.Tests)
+	require.Len(t, updateSuite.Cases, 1)
+	assert.Nil(t, updateSuite.Cases[0].Failure)
+}
@@ -0,0 +1,149 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateReports_Percentiles(t *testing.T) {
+	newRunConfig := func(report *ReportConfig) *RunConfig {
+		return &RunConfig{
+			OutputConfig: &OutputConfig{ReportConfig: report},
+			WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+				Name:            "select",
+				StatementConfig: &StatementConfig{Query: "SELECT 1"},
+			}}},
+		}
+	}
+	newMetric := func(t *testing.T) *Metric {
+		m, err := NewMetric()
+		require.NoError(t, err)
+		for i := 1; i <= 1000; i++ {
+			require.NoError(t, m.SubmitQueryResult(&QueryResult{ResponseTime: time.Duration(i) * time.Millisecond}))
+		}
+		return m
+	}
+	names := func(ps []*Percentile) []string {
+		res := make([]string, 0, len(ps))
+		for _, p := range ps {
+			res = append(res, p.Name)
+		}
+		return res
+	}
+
+	t.Run("should report default percentiles", func(t *testing.T) {
+		cfg := newRunConfig(nil)
+
+		require.NoError(t, calculateReports(cfg, []*Metric{newMetric(t)}))
+
+		report := cfg.WorkflowConfig.Scenarios[0].Report
+		assert.Equal(t, []string{"p50", "p90", "p95", "p99"}, names(report.Percentiles))
+	})
+
+	t.Run("should report configured percentiles in order", func(t *testing.T) {
+		cfg := newRunConfig(&ReportConfig{Percentiles: []float64{99.9, 50}})
+
+		require.NoError(t, calculateReports(cfg, []*Metric{newMetric(t)}))
+
+		report := cfg.WorkflowConfig.Scenarios[0].Report
+		require.Equal(t, []string{"p99.9", "p50"}, names(report.Percentiles))
+		p999, err := time.ParseDuration(report.Percentiles[0].Value)
+		require.NoError(t, err)
+		assert.InDelta(t, float64(999*time.Millisecond), float64(p999), float64(2*time.Millisecond))
+		assert.Empty(t, report.QueueDelayPercentiles, "closed model has no queue")
+	})
+
+	t.Run("should report queue delay of arrivals", func(t *testing.T) {
+		cfg := newRunConfig(&ReportConfig{Percentiles: []float64{50}})
+		m := newMetric(t)
+		require.NoError(t, m.SubmitQueueDelay(0))
+		require.NoError(t, m.SubmitQueueDelay(40*time.Millisecond))
+
+		require.NoError(t, calculateReports(cfg, []*Metric{m}))
+
+		report := cfg.WorkflowConfig.Scenarios[0].Report
+		assert.Equal(t, []string{"p50"}, names(report.QueueDelayPercentiles))
+		assert.Equal(t, "40ms", report.QueueDelayMax)
+	})
+}
+
+func TestCalculateReports_Hdr(t *testing.T) {
+	cfg := &RunConfig{
+		OutputConfig: &OutputConfig{ReportConfig: &ReportConfig{Recorder: recorderHdr, Percentiles: []float64{50, 99}}},
+		WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+			Name:            "select",
+			StatementConfig: &StatementConfig{Query: "SELECT 1"},
+		}}},
+	}
+	m, err := NewMetric()
+	require.NoError(t, err)
+	m.EnableHdr()
+	// One query stalled for a second, next iterations started late by schedule
+	require.NoError(t, m.SubmitQueryResult(&QueryResult{ResponseTime: time.Second}))
+	for i := 1; i <= 99; i++ {
+		require.NoError(t, m.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond, ScheduleDelay: time.Second - time.Duration(i)*10*time.Millisecond}))
+	}
+
+	require.NoError(t, calculateReports(cfg, []*Metric{m}))
+
+	report := cfg.WorkflowConfig.Scenarios[0].Report
+	require.Len(t, report.Percentiles, 2)
+	require.Len(t, report.CorrectedPercentiles, 2)
+	assert.Equal(t, "1ms", report.Percentiles[0].Value)
+	assert.Equal(t, "p50", report.CorrectedPercentiles[0].Name)
+	corrected, err := time.ParseDuration(report.CorrectedPercentiles[0].Value)
+	require.NoError(t, err)
+	assert.Greater(t, corrected, 400*time.Millisecond)
+	correctedMax, err := time.ParseDuration(report.CorrectedMax)
+	require.NoError(t, err)
+	assert.InDelta(t, float64(time.Second), float64(correctedMax), float64(time.Millisecond))
+}
+
+func TestGetErrorReports(t *testing.T) {
+	errMap := map[string]int64{"timeout": 2, "sqlstate_23505": 5, "eof": 2}
+	samples := map[string][]string{"timeout": {"context deadline exceeded"}, "sqlstate_23505": {"pq: duplicate key value", "pq: duplicate key id"}, "eof": {"EOF"}}
+
+	errs := getErrorReports(errMap, samples)
+
+	assert.Equal(t, []*ErrorReport{
+		{Class: "sqlstate_23505", Count: 5, Samples: []string{"pq: duplicate key value", "pq: duplicate key id"}},
+		{Class: "eof", Count: 2, Samples: []string{"EOF"}},
+		{Class: "timeout", Count: 2, Samples: []string{"context deadline exceeded"}},
+	}, errs)
+	assert.Equal(t, []string{"pq: duplicate key value", "EOF", "context deadline exceeded"}, getTopErrors(errs))
+}
+
+func TestMeanStdDev(t *testing.T) {
+	t.Run("should calculate mean and standard deviation", func(t *testing.T) {
+		m, err := NewMetric()
+		require.NoError(t, err)
+		for _, d := range []time.Duration{2, 4, 4, 4, 5, 5, 7, 9} {
+			require.NoError(t, m.SubmitQueryResult(&QueryResult{ResponseTime: d * time.Millisecond}))
+		}
+
+		mean, stdDev := meanStdDev(m.Td)
+
+		assert.InDelta(t, float64(5*time.Millisecond), float64(mean), float64(time.Microsecond))
+		assert.InDelta(t, float64(2*time.Millisecond), float64(stdDev), float64(time.Microsecond))
+	})
+
+	t.Run("should return zero for empty digest", func(t *testing.T) {
+		m, err := NewMetric()
+		require.NoError(t, err)
+
+		mean, stdDev := meanStdDev(m.Td)
+
+		assert.Zero(t, mean)
+		assert.Zero(t, stdDev)
+	})
+}
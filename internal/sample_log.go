@@ -0,0 +1,250 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	samplesFormatCSV    = "csv"
+	samplesFormatNDJSON = "ndjson"
+
+	samplesQueueSize  = 16384
+	samplesBufferSize = 64 * 1024
+)
+
+var samplesCSVHeader = []string{"time", "scenario", "thread_id", "statement", "latency_us", "rows", "error_class"}
+
+// SampleLog writes one record per query result to CSV or gzip compressed NDJSON file.
+// Records are queued and written in background, so threads are never blocked by disk;
+// record is dropped if queue is full.
+type SampleLog struct {
+	cfg    *SamplesConfig
+	logger *zerolog.Logger
+	path   string
+
+	records chan *sampleRecord
+	done    chan struct{}
+	dropped atomic.Int64
+	written int64
+	err     error // First write error, next records are discarded
+
+	file *os.File
+	buf  *bufio.Writer
+	gz   *gzip.Writer
+	csv  *csv.Writer
+	enc  *json.Encoder
+}
+
+type sampleRecord struct {
+	Time       time.Time `json:"time"` // Query start time
+	Scenario   string    `json:"scenario"`
+	ThreadId   int       `json:"thread_id"`
+	Statement  string    `json:"statement"`
+	LatencyUs  int64     `json:"latency_us"`
+	Rows       int64     `json:"rows"`
+	ErrorClass string    `json:"error_class,omitempty"`
+	Args       []string  `json:"args,omitempty"`
+}
+
+// sampleScenario is query observer of one scenario
+type sampleScenario struct {
+	log        *SampleLog
+	name       string
+	statements []string
+}
+
+func NewSampleLog(cfg *SamplesConfig, logger *zerolog.Logger) (*SampleLog, error) {
+	path := cfg.File
+	if path == "" {
+		ext := ".csv"
+		if cfg.Format == samplesFormatNDJSON {
+			ext = ".ndjson.gz"
+		}
+		path = fmt.Sprintf("loadhound_samples_%s%s", time.Now().Format(time.RFC3339), ext)
+	}
+	// #nosec G304 -- path to sample log is provided by user on purpose
+	f, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sample log: %w", err)
+	}
+
+	s := &SampleLog{
+		cfg:     cfg,
+		logger:  logger,
+		path:    path,
+		records: make(chan *sampleRecord, samplesQueueSize),
+		done:    make(chan struct{}),
+		file:    f,
+	}
+	var w io.Writer = f
+	if cfg.Format == samplesFormatNDJSON {
+		s.gz = gzip.NewWriter(f)
+		w = s.gz
+	}
+	s.buf = bufio.NewWriterSize(w, samplesBufferSize)
+
+	if cfg.Format == samplesFormatNDJSON {
+		s.enc = json.NewEncoder(s.buf)
+	} else {
+		s.csv = csv.NewWriter(s.buf)
+		header := samplesCSVHeader
+		if cfg.IncludeArgs {
+			header = append(header[:len(header):len(header)], "args")
+		}
+		if err := s.csv.Write(header); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to write sample log header: %w", err)
+		}
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// AddScenario returns observer which logs query results of scenario
+func (s *SampleLog) AddScenario(name string, statements []*StatementExecutor, _ []*Thread) QueryObserver {
+	sc := &sampleScenario{log: s, name: name}
+	for _, stmt := range statements {
+		sc.statements = append(sc.statements, stmt.Name)
+	}
+	return sc
+}
+
+func (sc *sampleScenario) ObserveQuery(threadId, statement int, q *QueryResult) {
+	if q == nil || statement >= len(sc.statements) {
+		return
+	}
+	if rate := sc.log.cfg.SampleRate; rate > 0 && rate < 1 && rand.Float64() >= rate {
+		return
+	}
+
+	start := q.StartTime
+	if start.IsZero() {
+		start = time.Now().Add(-q.ResponseTime)
+	}
+	rec := &sampleRecord{
+		Time:      start,
+		Scenario:  sc.name,
+		ThreadId:  threadId,
+		Statement: sc.statements[statement],
+		LatencyUs: q.ResponseTime.Microseconds(),
+		Rows:      q.RowsAffected,
+	}
+	if q.Err != nil {
+		rec.ErrorClass = errorClass(q.Err)
+	}
+	if sc.log.cfg.IncludeArgs && len(q.Args) > 0 {
+		rec.Args = formatSampleArgs(q.Args)
+	}
+
+	select {
+	case sc.log.records <- rec:
+	default:
+		sc.log.dropped.Add(1)
+	}
+}
+
+func (sc *sampleScenario) ObserveIteration() {}
+
+// Close writes queued records, flushes buffers and closes file
+func (s *SampleLog) Close() error {
+	close(s.records)
+	<-s.done
+
+	err := s.err
+	if s.csv != nil {
+		s.csv.Flush()
+		err = errors.Join(err, s.csv.Error())
+	}
+	err = errors.Join(err, s.buf.Flush())
+	if s.gz != nil {
+		err = errors.Join(err, s.gz.Close())
+	}
+	err = errors.Join(err, s.file.Close())
+
+	event := s.logger.Info()
+	if dropped := s.dropped.Load(); dropped > 0 {
+		event = s.logger.Warn().Int64("dropped", dropped)
+	}
+	event.Str("file", s.path).Int64("written", s.written).Msg("Sample log saved")
+	return err
+}
+
+func (s *SampleLog) run() {
+	defer close(s.done)
+	for rec := range s.records {
+		if s.err != nil {
+			continue
+		}
+		if err := s.write(rec); err != nil {
+			s.err = fmt.Errorf("failed to write sample log: %w", err)
+			s.logger.Error().Err(err).Str("file", s.path).Msg("Failed to write sample log, next records are discarded")
+			continue
+		}
+		s.written++
+	}
+}
+
+func (s *SampleLog) write(rec *sampleRecord) error {
+	if s.enc != nil {
+		return s.enc.Encode(rec)
+	}
+	row := []string{
+		rec.Time.Format(time.RFC3339Nano),
+		rec.Scenario,
+		strconv.Itoa(rec.ThreadId),
+		rec.Statement,
+		strconv.FormatInt(rec.LatencyUs, 10),
+		strconv.FormatInt(rec.Rows, 10),
+		rec.ErrorClass,
+	}
+	if s.cfg.IncludeArgs {
+		args := ""
+		if len(rec.Args) > 0 {
+			data, err := json.Marshal(rec.Args)
+			if err != nil {
+				return err
+			}
+			args = string(data)
+		}
+		row = append(row, args)
+	}
+	return s.csv.Write(row)
+}
+
+// Format bound arguments as strings, so any generated value can be written
+func formatSampleArgs(args []any) []string {
+	res := make([]string, len(args))
+	for idx, arg := range args {
+		switch v := arg.(type) {
+		case []byte:
+			res[idx] = string(v)
+		case time.Time:
+			res[idx] = v.Format(time.RFC3339Nano)
+		default:
+			res[idx] = fmt.Sprint(v)
+		}
+	}
+	return res
+}
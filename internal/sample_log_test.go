@@ -0,0 +1,89 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func observeSamples(t *testing.T, cfg *SamplesConfig) {
+	logger := zerolog.Nop()
+	samples, err := NewSampleLog(cfg, &logger)
+	require.NoError(t, err)
+
+	observer := samples.AddScenario("orders", []*StatementExecutor{{Name: "select"}, {Name: "update"}}, nil)
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	observer.ObserveQuery(1, 0, &QueryResult{Args: []any{42, "abc"}, RowsAffected: 3, StartTime: start, ResponseTime: 1500 * time.Microsecond})
+	observer.ObserveQuery(2, 1, &QueryResult{StartTime: start, ResponseTime: time.Millisecond, Err: context.DeadlineExceeded})
+	require.NoError(t, samples.Close())
+}
+
+func TestSampleLog_CSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.csv")
+	observeSamples(t, &SamplesConfig{File: path, IncludeArgs: true})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"time", "scenario", "thread_id", "statement", "latency_us", "rows", "error_class", "args"},
+		{"2025-01-02T03:04:05Z", "orders", "1", "select", "1500", "3", "", `["42","abc"]`},
+		{"2025-01-02T03:04:05Z", "orders", "2", "update", "1000", "0", "timeout", ""},
+	}, rows)
+}
+
+func TestSampleLog_NDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.ndjson.gz")
+	observeSamples(t, &SamplesConfig{File: path, Format: samplesFormatNDJSON})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	var records []map[string]any
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		records = append(records, rec)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "select", records[0]["statement"])
+	assert.Equal(t, float64(1500), records[0]["latency_us"])
+	assert.NotContains(t, records[0], "args")
+	assert.NotContains(t, records[0], "error_class")
+	assert.Equal(t, "timeout", records[1]["error_class"])
+}
+
+func TestSampleLog_SampleRate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.csv")
+	observeSamples(t, &SamplesConfig{File: path, SampleRate: 1e-12})
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "time,scenario,thread_id,statement,latency_us,rows,error_class\n", string(data))
+}
@@ -32,12 +32,23 @@ func NewScenarioDur(logger *zerolog.Logger, cfg *ScenarioConfig, threads []*Thre
 }
 
 func (sc *ScenarioDur) Run(ctx context.Context) error {
-	var wg sync.WaitGroup
-	timeOutCtx, cancel := context.WithTimeout(ctx, sc.cfg.Duration)
+	var (
+		wg     sync.WaitGroup
+		runErr error
+	)
+	duration := sc.cfg.Duration
+	if len(sc.cfg.Stages) > 0 {
+		duration = stagesDuration(sc.cfg.Stages)
+	}
+	timeOutCtx, cancel := context.WithTimeout(ctx, duration)
 	defer cancel()
 
-	// if user set ramp_up param to run threads gradually
-	if sc.cfg.RampUp > 0 {
+	// if user set stages, start and stop threads according to load profile
+	if len(sc.cfg.Stages) > 0 {
+		sc.Metric.SetStartTime(time.Now())
+		runErr = sc.runStages(ctx, timeOutCtx, &wg)
+	} else if sc.cfg.RampUp > 0 {
+		// if user set ramp_up param to run threads gradually
 		// Calculation ramp_up interval, min value is 10 millisecond
 		intervalDur := calculateRampUpInterval(sc.cfg.RampUp, sc.cfg.Threads)
 
@@ -47,11 +58,13 @@ func (sc *ScenarioDur) Run(ctx context.Context) error {
 
 		sc.Metric.SetStartTime(time.Now())
 		sc.logger.Debug().Str("ramp_up_interval", intervalDur.String()).Int("total_threads", len(sc.threads)).Msg("Ramp-up configuration calculated")
+	rampUp:
 		for _, thread := range sc.threads {
 			select {
 			case <-ctx.Done():
 				sc.logger.Warn().Msg("Context cancelled during ramp-up")
-				return ctx.Err()
+				runErr = ctx.Err()
+				break rampUp
 			case <-ticker.C:
 				wg.Add(1)
 				go thread.RunOnDur(timeOutCtx, &wg)
@@ -59,11 +72,13 @@ func (sc *ScenarioDur) Run(ctx context.Context) error {
 		}
 	} else {
 		sc.Metric.SetStartTime(time.Now())
+	start:
 		for _, thread := range sc.threads {
 			select {
 			case <-ctx.Done():
 				sc.logger.Warn().Msg("Context cancelled during ramp-up")
-				return ctx.Err()
+				runErr = ctx.Err()
+				break start
 			default:
 				wg.Add(1)
 				go thread.RunOnDur(timeOutCtx, &wg)
@@ -74,22 +89,66 @@ func (sc *ScenarioDur) Run(ctx context.Context) error {
 	// wait until all threads finish their work
 	wg.Wait()
 
-	// sum metrics from threads
+	// sum metrics from threads, also when scenario was cancelled
 	sc.Metric.SetStopTime(time.Now())
-	for _, th := range sc.threads {
-		snapshot := th.Metric.GetSnapshot()
-		sc.Metric.IterationsTotal += snapshot.IterationsTotal
-		sc.Metric.RowsAffected += snapshot.RowsAffected
-		sc.Metric.QueriesTotal += snapshot.QueriesTotal
-		sc.Metric.ErrorsTotal += snapshot.ErrorsTotal
-		if err := sc.Metric.Td.Merge(snapshot.Td); err != nil {
-			return err
+	if err := mergeThreadsMetrics(sc.Metric, sc.threads); err != nil {
+		return err
+	}
+	return runErr
+}
+
+// Keep active threads count in line with multi-stage load profile until timeOutCtx is done.
+// Active threads are always the first N threads of the pool, the most recently started
+// thread is stopped first.
+func (sc *ScenarioDur) runStages(ctx, timeOutCtx context.Context, wg *sync.WaitGroup) error {
+	ticker := time.NewTicker(rampUpMin)
+	defer ticker.Stop()
+
+	var (
+		active  int
+		stopChs = make([]chan struct{}, len(sc.threads))
+		doneChs = make([]chan struct{}, len(sc.threads))
+		startAt = time.Now()
+	)
+	sc.logger.Debug().Int("stages", len(sc.cfg.Stages)).Int("max_threads", len(sc.threads)).Msg("Multi-stage load profile started")
+	for {
+		target := stageTargetAt(sc.cfg.Stages, time.Since(startAt))
+		if target > len(sc.threads) {
+			target = len(sc.threads)
 		}
-		if len(snapshot.ErrMap) != 0 {
-			for k, v := range snapshot.ErrMap {
-				sc.Metric.ErrMap[k] = v
+		for active < target {
+			// Wait until previous run of the thread returns from its last query
+			if doneChs[active] != nil {
+				select {
+				case <-ctx.Done():
+					sc.logger.Warn().Msg("Context cancelled during load profile")
+					return ctx.Err()
+				case <-doneChs[active]:
+				}
 			}
+			stopCh, doneCh := make(chan struct{}), make(chan struct{})
+			stopChs[active], doneChs[active] = stopCh, doneCh
+
+			thread := sc.threads[active]
+			wg.Add(1)
+			go func() {
+				defer close(doneCh)
+				thread.RunOnStage(timeOutCtx, stopCh, wg)
+			}()
+			active++
+		}
+		for active > target {
+			active--
+			close(stopChs[active])
+		}
+
+		select {
+		case <-ctx.Done():
+			sc.logger.Warn().Msg("Context cancelled during load profile")
+			return ctx.Err()
+		case <-timeOutCtx.Done():
+			return nil
+		case <-ticker.C:
 		}
 	}
-	return nil
 }
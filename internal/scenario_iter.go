@@ -32,7 +32,10 @@ func NewScenarioIter(logger *zerolog.Logger, cfg *ScenarioConfig, threads []*Thr
 }
 
 func (sc *ScenarioIter) Run(ctx context.Context) error {
-	var wg sync.WaitGroup
+	var (
+		wg     sync.WaitGroup
+		runErr error
+	)
 
 	// if user set ramp_up param to run threads gradually
 	if sc.cfg.RampUp > 0 {
@@ -45,11 +48,13 @@ func (sc *ScenarioIter) Run(ctx context.Context) error {
 
 		sc.Metric.SetStartTime(time.Now())
 		sc.logger.Debug().Str("ramp_up_interval", intervalDur.String()).Int("total_threads", len(sc.threads)).Msg("Ramp-up configuration calculated")
+	rampUp:
 		for _, thread := range sc.threads {
 			select {
 			case <-ctx.Done():
 				sc.logger.Warn().Msg("Context cancelled during ramp-up")
-				return ctx.Err()
+				runErr = ctx.Err()
+				break rampUp
 			case <-ticker.C:
 				wg.Add(1)
 				go thread.RunOnIter(ctx, &wg, sc.cfg.Iterations)
@@ -57,10 +62,12 @@ func (sc *ScenarioIter) Run(ctx context.Context) error {
 		}
 	} else {
 		sc.Metric.SetStartTime(time.Now())
+	start:
 		for _, thread := range sc.threads {
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				runErr = ctx.Err()
+				break start
 			default:
 				wg.Add(1)
 				go thread.RunOnIter(ctx, &wg, sc.cfg.Iterations)
@@ -71,22 +78,10 @@ func (sc *ScenarioIter) Run(ctx context.Context) error {
 	// wait until all threads finish their work
 	wg.Wait()
 
-	// sum metrics from threads
+	// sum metrics from threads, also when scenario was cancelled
 	sc.Metric.SetStopTime(time.Now())
-	for _, th := range sc.threads {
-		snapshot := th.Metric.GetSnapshot()
-		sc.Metric.IterationsTotal += snapshot.IterationsTotal
-		sc.Metric.RowsAffected += snapshot.RowsAffected
-		sc.Metric.QueriesTotal += snapshot.QueriesTotal
-		sc.Metric.ErrorsTotal += snapshot.ErrorsTotal
-		if err := sc.Metric.Td.Merge(snapshot.Td); err != nil {
-			return err
-		}
-		if len(snapshot.ErrMap) != 0 {
-			for k, v := range snapshot.ErrMap {
-				sc.Metric.ErrMap[k] = v
-			}
-		}
+	if err := mergeThreadsMetrics(sc.Metric, sc.threads); err != nil {
+		return err
 	}
-	return nil
+	return runErr
 }
@@ -0,0 +1,123 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ScenarioRate runs open-model load: queries are started at configured rate
+// regardless of their latency, threads work as bounded worker pool.
+type ScenarioRate struct {
+	logger  *zerolog.Logger
+	cfg     *ScenarioConfig
+	threads []*Thread
+	Metric  *Metric
+}
+
+func NewScenarioRate(logger *zerolog.Logger, cfg *ScenarioConfig, threads []*Thread, m *Metric) *ScenarioRate {
+	return &ScenarioRate{
+		logger:  logger,
+		cfg:     cfg,
+		threads: threads,
+		Metric:  m,
+	}
+}
+
+func (sc *ScenarioRate) Run(ctx context.Context) error {
+	var (
+		wg   sync.WaitGroup
+		idle atomic.Int64
+	)
+	timeOutCtx, cancel := context.WithTimeout(ctx, sc.cfg.Duration)
+	defer cancel()
+
+	// Queue holds arrivals which were scheduled while all workers are busy
+	arrivals := make(chan time.Time, len(sc.threads))
+
+	sc.Metric.SetStartTime(time.Now())
+	for _, thread := range sc.threads {
+		wg.Add(1)
+		go thread.RunOnArrivals(timeOutCtx, arrivals, &idle, &wg)
+	}
+	// Workers stop before timeout only when feeder rows are exhausted, nobody takes arrivals then
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+
+	sc.logger.Debug().Int("rate", sc.cfg.Rate).Str("ramp_up", sc.cfg.RampUp.String()).Int("workers", len(sc.threads)).Msg("Arrival-rate scenario started")
+	err := sc.dispatch(ctx, timeOutCtx, arrivals, &idle, workersDone)
+	close(arrivals)
+
+	// wait until all threads finish their work
+	wg.Wait()
+
+	// sum metrics from threads, also when scenario was cancelled
+	sc.Metric.SetStopTime(time.Now())
+	if mergeErr := mergeThreadsMetrics(sc.Metric, sc.threads); mergeErr != nil {
+		return mergeErr
+	}
+	return err
+}
+
+// Schedule arrivals according to rate until timeOutCtx is done or all workers have stopped.
+// Arrival is delayed when no worker is free but queue has space, otherwise it is dropped.
+func (sc *ScenarioRate) dispatch(ctx, timeOutCtx context.Context, arrivals chan time.Time, idle *atomic.Int64, workersDone <-chan struct{}) error {
+	ticker := time.NewTicker(rampUpMin)
+	defer ticker.Stop()
+
+	var (
+		scheduled int64
+		startAt   = time.Now()
+	)
+	for {
+		now := time.Now()
+		expected := expectedArrivals(sc.cfg.Rate, sc.cfg.RampUp, now.Sub(startAt))
+		for ; scheduled < expected; scheduled++ {
+			if idle.Load() > int64(len(arrivals)) {
+				arrivals <- now
+				continue
+			}
+			select {
+			case arrivals <- now:
+				sc.Metric.AddDelayed()
+			default:
+				sc.Metric.AddDropped()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			sc.logger.Warn().Msg("Context cancelled during arrivals dispatch")
+			return ctx.Err()
+		case <-timeOutCtx.Done():
+			return nil
+		case <-workersDone:
+			sc.logger.Info().Msg("All workers stopped, arrivals dispatch finished")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Get number of arrivals which must be scheduled at elapsed time since scenario start.
+// During rampUp the rate grows linearly from 0 to rate.
+func expectedArrivals(rate int, rampUp, elapsed time.Duration) int64 {
+	r := float64(rate)
+	if elapsed < rampUp {
+		return int64(r * elapsed.Seconds() * elapsed.Seconds() / (2 * rampUp.Seconds()))
+	}
+	return int64(r*rampUp.Seconds()/2 + r*(elapsed-rampUp).Seconds())
+}
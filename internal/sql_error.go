@@ -0,0 +1,81 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+
+	mysqlLockDeadlock uint16 = 1213
+)
+
+// Check if error is a transaction serialization failure
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqSerializationFailure
+	}
+	return false
+}
+
+// Check if error is a deadlock detected by database
+func isDeadlock(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqDeadlockDetected
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlLockDeadlock
+	}
+	return false
+}
+
+// Get class of error used to group errors in report and as metrics label.
+// Database errors are classified by driver code: "sqlstate_23505" for Postgres, "mysql_1062" for MySQL,
+// common client side failures have named classes.
+func errorClass(err error) string {
+	switch {
+	case isSerializationFailure(err):
+		return "serialization_failure"
+	case isDeadlock(err):
+		return "deadlock"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn):
+		return "bad_connection"
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "eof"
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return "sqlstate_" + string(pqErr.Code)
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return fmt.Sprintf("mysql_%d", mysqlErr.Number)
+	}
+	return "other"
+}
@@ -0,0 +1,76 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxConflictErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		serialization bool
+		deadlock      bool
+	}{
+		{name: "postgres serialization failure", err: &pq.Error{Code: "40001"}, serialization: true},
+		{name: "postgres deadlock", err: &pq.Error{Code: "40P01"}, deadlock: true},
+		{name: "wrapped postgres deadlock", err: fmt.Errorf("commit: %w", &pq.Error{Code: "40P01"}), deadlock: true},
+		{name: "postgres unique violation", err: &pq.Error{Code: "23505"}},
+		{name: "mysql deadlock", err: &mysql.MySQLError{Number: 1213}, deadlock: true},
+		{name: "mysql duplicate entry", err: &mysql.MySQLError{Number: 1062}},
+		{name: "generic error", err: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.serialization, isSerializationFailure(tt.err))
+			assert.Equal(t, tt.deadlock, isDeadlock(tt.err))
+		})
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	connRefused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, expected: "serialization_failure"},
+		{name: "deadlock", err: &mysql.MySQLError{Number: 1213}, expected: "deadlock"},
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), expected: "timeout"},
+		{name: "canceled", err: context.Canceled, expected: "canceled"},
+		{name: "connection refused", err: fmt.Errorf("connect: %w", connRefused), expected: "connection_refused"},
+		{name: "bad connection", err: driver.ErrBadConn, expected: "bad_connection"},
+		{name: "mysql invalid connection", err: mysql.ErrInvalidConn, expected: "bad_connection"},
+		{name: "eof", err: io.EOF, expected: "eof"},
+		{name: "unexpected eof", err: io.ErrUnexpectedEOF, expected: "eof"},
+		{name: "postgres code", err: &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint"}, expected: "sqlstate_23505"},
+		{name: "mysql number", err: fmt.Errorf("exec: %w", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1'"}), expected: "mysql_1062"},
+		{name: "generic error", err: assert.AnError, expected: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, errorClass(tt.err))
+		})
+	}
+}
@@ -9,31 +9,50 @@ package internal
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
 )
 
 type Thread struct {
-	Id                int
-	Metric            *Metric
-	statementExecutor *StatementExecutor
-	logger            *zerolog.Logger
+	Id             int
+	Metric         *Metric
+	scriptExecutor *ScriptExecutor
+	logger         *zerolog.Logger
+	active         atomic.Bool // Set while thread is running
+	gen            *GenContext // State of query argument generators
 }
 
-func NewThread(id int, metric *Metric, statementExecutor *StatementExecutor, logger *zerolog.Logger) *Thread {
+func NewThread(id int, metric *Metric, scriptExecutor *ScriptExecutor, logger *zerolog.Logger) *Thread {
 	threadLogger := logger.With().Int("thread_id", id).Logger()
 	return &Thread{
-		Id:                id,
-		Metric:            metric,
-		statementExecutor: statementExecutor,
-		logger:            &threadLogger,
+		Id:             id,
+		Metric:         metric,
+		scriptExecutor: scriptExecutor,
+		logger:         &threadLogger,
+		gen:            NewGenContext(id, 0, 1),
 	}
 }
 
+// IsActive reports whether thread is running at the moment
+func (t *Thread) IsActive() bool {
+	return t.active.Load()
+}
+
 func (t *Thread) RunOnDur(ctx context.Context, wg *sync.WaitGroup) {
+	t.RunOnStage(ctx, nil, wg)
+}
+
+// RunOnStage works like RunOnDur, but additionally stops when stop channel is closed.
+// Query in progress is not interrupted by stop, only by context cancellation.
+func (t *Thread) RunOnStage(ctx context.Context, stop <-chan struct{}, wg *sync.WaitGroup) {
+	t.active.Store(true)
 	defer func() {
+		t.active.Store(false)
 		wg.Done()
 		t.Metric.SetStopTime(time.Now())
 	}()
@@ -48,9 +67,62 @@ func (t *Thread) RunOnDur(ctx context.Context, wg *sync.WaitGroup) {
 		case <-ctx.Done():
 			t.logger.Debug().Int("executions_completed", executionCount).Msg("Thread stopped due to context cancellation")
 			return
+		case <-stop:
+			t.logger.Debug().Int("executions_completed", executionCount).Msg("Thread stopped by load profile")
+			return
 		default:
 		}
-		t.exec(ctx)
+		if !t.exec(ctx, time.Time{}) {
+			t.logger.Info().Int("executions_completed", executionCount).Msg("Thread stopped, feeder rows are exhausted")
+			return
+		}
+		executionCount++
+		t.Metric.AddIter()
+
+		if executionCount%100 == 0 {
+			t.logger.Debug().Int("executions_completed", executionCount).Msg("Thread execution progress")
+		}
+	}
+}
+
+// RunOnArrivals works as open-model worker: executes one iteration per arrival
+// until arrivals channel is closed or context is cancelled.
+// idle counter is incremented while the thread waits for the next arrival.
+func (t *Thread) RunOnArrivals(ctx context.Context, arrivals <-chan time.Time, idle *atomic.Int64, wg *sync.WaitGroup) {
+	t.active.Store(true)
+	defer func() {
+		t.active.Store(false)
+		wg.Done()
+		t.Metric.SetStopTime(time.Now())
+	}()
+
+	startTime := time.Now()
+	t.Metric.SetStartTime(startTime)
+	t.logger.Debug().Time("start_time", startTime).Msg("Thread started (arrival-rate)")
+
+	executionCount := 0
+	for {
+		idle.Add(1)
+		select {
+		case <-ctx.Done():
+			idle.Add(-1)
+			t.logger.Debug().Int("executions_completed", executionCount).Msg("Thread stopped due to context cancellation")
+			return
+		case arrivedAt, ok := <-arrivals:
+			idle.Add(-1)
+			if !ok {
+				t.logger.Debug().Int("executions_completed", executionCount).Msg("Thread stopped, no more arrivals")
+				return
+			}
+			// Time in queue is recorded with any recorder, it's the main sign of overloaded open-model run
+			if err := t.Metric.SubmitQueueDelay(time.Since(arrivedAt)); err != nil {
+				t.logger.Error().Err(err).Msg("Failed to submit queue delay")
+			}
+			if !t.exec(ctx, arrivedAt) {
+				t.logger.Info().Int("executions_completed", executionCount).Msg("Thread stopped, feeder rows are exhausted")
+				return
+			}
+		}
 		executionCount++
 		t.Metric.AddIter()
 
@@ -61,7 +133,9 @@ func (t *Thread) RunOnDur(ctx context.Context, wg *sync.WaitGroup) {
 }
 
 func (t *Thread) RunOnIter(ctx context.Context, wg *sync.WaitGroup, iterations int) {
+	t.active.Store(true)
 	defer func() {
+		t.active.Store(false)
 		wg.Done()
 		t.Metric.SetStopTime(time.Now())
 	}()
@@ -77,7 +151,10 @@ func (t *Thread) RunOnIter(ctx context.Context, wg *sync.WaitGroup, iterations i
 			return
 		default:
 		}
-		t.exec(ctx)
+		if !t.exec(ctx, time.Time{}) {
+			t.logger.Info().Int("completed_iterations", iter).Int("total_iterations", iterations).Msg("Thread stopped, feeder rows are exhausted")
+			return
+		}
 		t.Metric.AddIter()
 
 		if iterations >= 10 && (iter+1)%(iterations/10) == 0 {
@@ -87,15 +164,104 @@ func (t *Thread) RunOnIter(ctx context.Context, wg *sync.WaitGroup, iterations i
 	t.logger.Debug().Int("completed_iterations", iterations).Msg("Thread completed all iterations")
 }
 
-func (t *Thread) exec(ctx context.Context) {
+// Execute script statements in sequence, iteration stops on the first failed statement.
+// If script has transaction, statements are executed inside it.
+// intended is start of iteration by arrival schedule, the delay of actual start is recorded with query results,
+// zero intended means iteration is not scheduled. Paced iterations are corrected by pacing as expected interval instead.
+// False is returned when feeder has no rows for the thread, the thread must stop then.
+func (t *Thread) exec(ctx context.Context, intended time.Time) bool {
 	start := time.Now()
-	queryResult := t.statementExecutor.Fn(ctx)
-	if err := t.Metric.SubmitQueryResult(queryResult); err != nil {
-		t.logger.Error().Err(queryResult.Err).Str("duration", queryResult.ResponseTime.String()).Str("query", t.statementExecutor.Query).Msg("Query execution failed")
+	if intended.IsZero() || intended.After(start) {
+		intended = start
+	}
+	delay := start.Sub(intended)
+	var expectedInterval time.Duration
+	if t.scriptExecutor.CorrectOmission {
+		expectedInterval = t.scriptExecutor.Pacing
+	}
+	defer EvaluatePacing(start, t.scriptExecutor.Pacing)
+
+	t.gen.Reset()
+	execCtx := ContextWithGen(ctx, t.gen)
+	var tx Tx
+	if t.scriptExecutor.Tx != nil {
+		var err error
+		if tx, err = t.scriptExecutor.Tx.Begin(ctx); err != nil {
+			// Begin cancelled by stopped scenario is not a database failure
+			if ctx.Err() != nil {
+				return true
+			}
+			t.Metric.AddError(err)
+			t.logger.Error().Err(err).Msg("Failed to begin transaction")
+			return true
+		}
+		execCtx = ContextWithTx(execCtx, tx)
+	}
+
+	failed, exhausted := false, false
+	for idx, statementExecutor := range t.scriptExecutor.Statements {
+		queryResult := statementExecutor.Exec(execCtx)
+		queryResult.ScheduleDelay = delay
+		queryResult.ExpectedInterval = expectedInterval
+		// Query cancelled by stopped scenario is not a database failure, drop its result
+		if queryResult.Err != nil && ctx.Err() != nil {
+			failed = true
+			break
+		}
+		// Query without arguments wasn't sent, iteration is not counted
+		if errors.Is(queryResult.Err, ErrFeederExhausted) {
+			failed, exhausted = true, true
+			break
+		}
+		if err := t.Metric.SubmitQueryResult(queryResult); err != nil {
+			t.logger.Error().Err(queryResult.Err).Str("duration", queryResult.ResponseTime.String()).Str("query", statementExecutor.Query).Msg("Query execution failed")
+		}
+		for _, observer := range t.scriptExecutor.Observers {
+			observer.ObserveQuery(t.Id, idx, queryResult)
+		}
+		if idx < len(t.Metric.Statements) {
+			if err := t.Metric.Statements[idx].SubmitQueryResult(queryResult); err != nil {
+				t.logger.Error().Err(err).Str("statement", statementExecutor.Name).Msg("Failed to submit statement result")
+			}
+		}
+		if queryResult.Slow {
+			t.logger.Warn().Str("statement", statementExecutor.Name).Str("duration", queryResult.ResponseTime.String()).Interface("args", queryResult.Args).Msg("Slow query")
+		}
+		if queryResult.Err != nil {
+			t.logger.Error().Err(queryResult.Err).Str("duration", queryResult.ResponseTime.String()).Str("query", statementExecutor.Query).Msg("Query execution failed")
+			failed = true
+			break
+		}
+		t.logger.Trace().Str("duration", queryResult.ResponseTime.String()).Msg("Query executed successfully")
+	}
+
+	if tx != nil {
+		t.endTx(ctx, tx, failed)
+	}
+	if ctx.Err() != nil || exhausted {
+		return !exhausted
+	}
+	if err := t.Metric.SubmitIteration(time.Since(start)); err != nil {
+		t.logger.Error().Err(err).Msg("Failed to submit iteration duration")
+	}
+	for _, observer := range t.scriptExecutor.Observers {
+		observer.ObserveIteration()
+	}
+	return true
+}
+
+// Commit or rollback transaction, transaction with failed statement is always rolled back.
+// Failures after ctx is done come from stopped scenario and are not recorded, like cancelled queries.
+func (t *Thread) endTx(ctx context.Context, tx Tx, failed bool) {
+	if failed || t.scriptExecutor.Tx.Rollback {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) && ctx.Err() == nil {
+			t.Metric.AddError(err)
+			t.logger.Error().Err(err).Msg("Failed to rollback transaction")
+		}
+		return
 	}
-	if queryResult.Err != nil {
-		t.logger.Error().Err(queryResult.Err).Str("duration", queryResult.ResponseTime.String()).Str("query", t.statementExecutor.Query).Msg("Query execution failed")
+	if err := tx.Commit(); err != nil && ctx.Err() == nil {
+		t.Metric.AddError(err)
+		t.logger.Error().Err(err).Msg("Failed to commit transaction")
 	}
-	t.logger.Trace().Str("duration", queryResult.ResponseTime.String()).Msg("Query executed successfully")
-	EvaluatePacing(start, t.statementExecutor.Pacing)
 }
@@ -9,10 +9,13 @@ package internal
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -40,16 +43,17 @@ func TestNewThread(t *testing.T) {
 	require.NoError(t, err)
 
 	executor := &StatementExecutor{
-		Query:  "SELECT 1",
-		Pacing: 0,
+		Query: "SELECT 1",
 	}
 
-	thread := NewThread(42, metric, executor, &logger)
+	script := &ScriptExecutor{Statements: []*StatementExecutor{executor}}
+
+	thread := NewThread(42, metric, script, &logger)
 
 	assert.NotNil(t, thread)
 	assert.Equal(t, 42, thread.Id)
 	assert.Equal(t, metric, thread.Metric)
-	assert.Equal(t, executor, thread.statementExecutor)
+	assert.Equal(t, script, thread.scriptExecutor)
 	assert.NotNil(t, thread.logger)
 }
 
@@ -61,8 +65,7 @@ func TestThread_RunOnDur(t *testing.T) {
 	t.Run("should run until context cancellation", func(t *testing.T) {
 		executionCount := 0
 		executor := &StatementExecutor{
-			Query:  "SELECT 1",
-			Pacing: 0,
+			Query: "SELECT 1",
 			Fn: func(ctx context.Context) *QueryResult {
 				executionCount++
 				return &QueryResult{
@@ -73,7 +76,7 @@ func TestThread_RunOnDur(t *testing.T) {
 			},
 		}
 
-		thread := NewThread(1, metric, executor, &logger)
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 		defer cancel()
@@ -104,8 +107,7 @@ func TestThread_RunOnDur(t *testing.T) {
 		require.NoError(t, err)
 
 		executor := &StatementExecutor{
-			Query:  "INVALID SQL",
-			Pacing: 0,
+			Query: "INVALID SQL",
 			Fn: func(ctx context.Context) *QueryResult {
 				return &QueryResult{
 					RowsAffected: 0,
@@ -115,7 +117,7 @@ func TestThread_RunOnDur(t *testing.T) {
 			},
 		}
 
-		thread := NewThread(1, metric, executor, &logger)
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
 		defer cancel()
@@ -127,7 +129,7 @@ func TestThread_RunOnDur(t *testing.T) {
 
 		// Should have recorded errors
 		assert.Greater(t, metric.ErrorsTotal, int64(0))
-		assert.Greater(t, metric.ErrMap[assert.AnError.Error()], int64(0))
+		assert.Greater(t, metric.ErrMap["other"], int64(0))
 	})
 }
 
@@ -140,8 +142,7 @@ func TestThread_RunOnIter(t *testing.T) {
 
 		executionCount := 0
 		executor := &StatementExecutor{
-			Query:  "SELECT 1",
-			Pacing: 0,
+			Query: "SELECT 1",
 			Fn: func(ctx context.Context) *QueryResult {
 				executionCount++
 				return &QueryResult{
@@ -152,7 +153,7 @@ func TestThread_RunOnIter(t *testing.T) {
 			},
 		}
 
-		thread := NewThread(1, metric, executor, &logger)
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
 
 		ctx := context.Background()
 		var wg sync.WaitGroup
@@ -177,8 +178,7 @@ func TestThread_RunOnIter(t *testing.T) {
 
 		executionCount := 0
 		executor := &StatementExecutor{
-			Query:  "SELECT 1",
-			Pacing: 10 * time.Millisecond, // Add some delay
+			Query: "SELECT 1",
 			Fn: func(ctx context.Context) *QueryResult {
 				executionCount++
 				return &QueryResult{
@@ -189,7 +189,7 @@ func TestThread_RunOnIter(t *testing.T) {
 			},
 		}
 
-		thread := NewThread(1, metric, executor, &logger)
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}, Pacing: 10 * time.Millisecond}, &logger)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 25*time.Millisecond)
 		defer cancel()
@@ -210,8 +210,7 @@ func TestThread_RunOnIter(t *testing.T) {
 
 		executionCount := 0
 		executor := &StatementExecutor{
-			Query:  "SELECT 1",
-			Pacing: 0,
+			Query: "SELECT 1",
 			Fn: func(ctx context.Context) *QueryResult {
 				executionCount++
 				return &QueryResult{
@@ -222,7 +221,7 @@ func TestThread_RunOnIter(t *testing.T) {
 			},
 		}
 
-		thread := NewThread(1, metric, executor, &logger)
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
 
 		ctx := context.Background()
 		var wg sync.WaitGroup
@@ -235,6 +234,60 @@ func TestThread_RunOnIter(t *testing.T) {
 		assert.Equal(t, int64(0), metric.IterationsTotal)
 		assert.Equal(t, int64(0), metric.QueriesTotal)
 	})
+
+	t.Run("should pass thread state to generators", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+
+		var threadIds []int
+		var iterations []int64
+		executor := &StatementExecutor{
+			Query: "SELECT $1",
+			Fn: func(ctx context.Context) *QueryResult {
+				gc := genFromContext(ctx)
+				threadIds = append(threadIds, gc.ThreadId)
+				iterations = append(iterations, gc.Iteration)
+				return &QueryResult{ResponseTime: time.Millisecond}
+			},
+		}
+
+		thread := NewThread(7, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		thread.RunOnIter(context.Background(), &wg, 3)
+
+		assert.Equal(t, []int{7, 7, 7}, threadIds)
+		assert.Equal(t, []int64{0, 1, 2}, iterations)
+	})
+
+	t.Run("should stop when feeder is exhausted", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+
+		executionCount := 0
+		executor := &StatementExecutor{
+			Query: "SELECT $1",
+			Fn: func(ctx context.Context) *QueryResult {
+				executionCount++
+				if executionCount > 3 {
+					return &QueryResult{Err: fmt.Errorf("%w: feeder ids", ErrFeederExhausted)}
+				}
+				return &QueryResult{RowsAffected: 1, ResponseTime: time.Millisecond}
+			},
+		}
+
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		thread.RunOnIter(context.Background(), &wg, 10)
+
+		// Exhausted feeder is not a query failure
+		assert.Equal(t, 4, executionCount)
+		assert.Equal(t, int64(3), metric.IterationsTotal)
+		assert.Equal(t, int64(3), metric.QueriesTotal)
+		assert.Equal(t, int64(0), metric.ErrorsTotal)
+	})
 }
 
 func TestThread_exec(t *testing.T) {
@@ -245,8 +298,7 @@ func TestThread_exec(t *testing.T) {
 	t.Run("should execute query and handle pacing", func(t *testing.T) {
 		executed := false
 		executor := &StatementExecutor{
-			Query:  "SELECT 1",
-			Pacing: 20 * time.Millisecond,
+			Query: "SELECT 1",
 			Fn: func(ctx context.Context) *QueryResult {
 				executed = true
 				return &QueryResult{
@@ -257,10 +309,10 @@ func TestThread_exec(t *testing.T) {
 			},
 		}
 
-		thread := NewThread(1, metric, executor, &logger)
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}, Pacing: 20 * time.Millisecond}, &logger)
 
 		start := time.Now()
-		thread.exec(context.Background())
+		thread.exec(context.Background(), time.Time{})
 		elapsed := time.Since(start)
 
 		// Should have executed the query
@@ -281,8 +333,7 @@ func TestThread_exec(t *testing.T) {
 		require.NoError(t, err)
 
 		executor := &StatementExecutor{
-			Query:  "INVALID SQL",
-			Pacing: 0,
+			Query: "INVALID SQL",
 			Fn: func(ctx context.Context) *QueryResult {
 				return &QueryResult{
 					RowsAffected: 0,
@@ -292,13 +343,294 @@ func TestThread_exec(t *testing.T) {
 			},
 		}
 
-		thread := NewThread(1, metric, executor, &logger)
-		thread.exec(context.Background())
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+		thread.exec(context.Background(), time.Time{})
 
 		// Should have recorded the error
 		assert.Equal(t, int64(1), metric.QueriesTotal)
 		assert.Equal(t, int64(1), metric.ErrorsTotal)
-		assert.Equal(t, int64(1), metric.ErrMap[assert.AnError.Error()])
+		assert.Equal(t, int64(1), metric.ErrMap["other"])
+	})
+
+	t.Run("should drop result of query cancelled by context", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		executor := &StatementExecutor{
+			Query: "SELECT pg_sleep(10)",
+			Fn: func(ctx context.Context) *QueryResult {
+				cancel()
+				return &QueryResult{ResponseTime: time.Millisecond, Err: context.Canceled}
+			},
+		}
+
+		thread := NewThread(1, metric, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+		thread.exec(ctx, time.Time{})
+
+		assert.Equal(t, int64(0), metric.QueriesTotal)
+		assert.Equal(t, int64(0), metric.ErrorsTotal)
+		assert.Equal(t, uint64(0), metric.IterTd.Count())
+	})
+}
+
+func TestThread_exec_Script(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	newExecutor := func(name string, err error, calls *[]string) *StatementExecutor {
+		return &StatementExecutor{
+			Name:  name,
+			Query: "SELECT 1",
+			Fn: func(ctx context.Context) *QueryResult {
+				*calls = append(*calls, name)
+				return &QueryResult{RowsAffected: 1, ResponseTime: time.Millisecond, Err: err}
+			},
+		}
+	}
+
+	t.Run("should execute statements in order", func(t *testing.T) {
+		metric, err := NewScriptMetric(3)
+		require.NoError(t, err)
+
+		var calls []string
+		script := &ScriptExecutor{Statements: []*StatementExecutor{
+			newExecutor("select", nil, &calls),
+			newExecutor("update", nil, &calls),
+			newExecutor("insert", nil, &calls),
+		}}
+
+		thread := NewThread(1, metric, script, &logger)
+		thread.exec(context.Background(), time.Time{})
+
+		assert.Equal(t, []string{"select", "update", "insert"}, calls)
+		assert.Equal(t, int64(3), metric.QueriesTotal)
+		for _, stmt := range metric.Statements {
+			assert.Equal(t, int64(1), stmt.QueriesTotal)
+		}
+		assert.Equal(t, uint64(1), metric.IterTd.Count())
+	})
+
+	t.Run("should stop iteration on failed statement", func(t *testing.T) {
+		metric, err := NewScriptMetric(3)
+		require.NoError(t, err)
+
+		var calls []string
+		script := &ScriptExecutor{Statements: []*StatementExecutor{
+			newExecutor("select", nil, &calls),
+			newExecutor("update", assert.AnError, &calls),
+			newExecutor("insert", nil, &calls),
+		}}
+
+		thread := NewThread(1, metric, script, &logger)
+		thread.exec(context.Background(), time.Time{})
+
+		assert.Equal(t, []string{"select", "update"}, calls)
+		assert.Equal(t, int64(2), metric.QueriesTotal)
+		assert.Equal(t, int64(1), metric.ErrorsTotal)
+		assert.Equal(t, int64(1), metric.Statements[1].ErrorsTotal)
+		assert.Equal(t, int64(0), metric.Statements[2].QueriesTotal)
+	})
+
+	t.Run("should measure schedule delay from intended start", func(t *testing.T) {
+		metric, err := NewScriptMetric(1)
+		require.NoError(t, err)
+		metric.EnableHdr()
+
+		var calls []string
+		script := &ScriptExecutor{Statements: []*StatementExecutor{newExecutor("select", nil, &calls)}, Pacing: 50 * time.Millisecond, CorrectOmission: true}
+
+		thread := NewThread(1, metric, script, &logger)
+		start := time.Now()
+		thread.exec(context.Background(), start.Add(-2*time.Second))
+
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "late iteration still waits for pacing, load doesn't depend on recorder")
+		assert.Equal(t, int64(1), metric.Hdr.TotalCount())
+		assert.Less(t, metric.Hdr.Max(), int64(10*time.Millisecond/time.Microsecond))
+		assert.GreaterOrEqual(t, metric.CorrectedHdr.Max(), int64(2*time.Second/time.Microsecond))
+	})
+
+	t.Run("should record iterations missed during stall when correcting omission", func(t *testing.T) {
+		metric, err := NewScriptMetric(1)
+		require.NoError(t, err)
+		metric.EnableHdr()
+
+		stall := &StatementExecutor{Name: "select", Query: "SELECT 1", Fn: func(ctx context.Context) *QueryResult {
+			return &QueryResult{ResponseTime: 100 * time.Millisecond}
+		}}
+		script := &ScriptExecutor{Statements: []*StatementExecutor{stall}, Pacing: 10 * time.Millisecond, CorrectOmission: true}
+
+		thread := NewThread(1, metric, script, &logger)
+		thread.exec(context.Background(), time.Time{})
+
+		assert.Equal(t, int64(1), metric.Hdr.TotalCount())
+		assert.Equal(t, int64(10), metric.CorrectedHdr.TotalCount(), "the stalled and 9 missed iterations")
+
+		script.CorrectOmission = false
+		thread.exec(context.Background(), time.Time{})
+		assert.Equal(t, int64(11), metric.CorrectedHdr.TotalCount(), "iterations are not corrected without hdr recorder")
+	})
+
+	t.Run("should pass results to observers", func(t *testing.T) {
+		metric, err := NewScriptMetric(2)
+		require.NoError(t, err)
+
+		var calls []string
+		observer := &fakeObserver{}
+		script := &ScriptExecutor{
+			Statements: []*StatementExecutor{newExecutor("select", nil, &calls), newExecutor("update", nil, &calls)},
+			Observers:  []QueryObserver{observer},
+		}
+
+		thread := NewThread(1, metric, script, &logger)
+		thread.exec(context.Background(), time.Time{})
+
+		assert.Equal(t, []int{0, 1}, observer.statements)
+		assert.Equal(t, 1, observer.iterations)
+	})
+}
+
+// fakeObserver records observed statements
+type fakeObserver struct {
+	statements []int
+	iterations int
+}
+
+func (o *fakeObserver) ObserveQuery(threadId, statement int, q *QueryResult) {
+	o.statements = append(o.statements, statement)
+}
+
+func (o *fakeObserver) ObserveIteration() {
+	o.iterations++
+}
+
+// fakeTx records how transaction was ended
+type fakeTx struct {
+	Tx
+	committed  bool
+	rolledBack bool
+	commitErr  error
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.rolledBack = true
+	return nil
+}
+
+func TestThread_exec_Transaction(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	newScript := func(tx *fakeTx, rollback bool, stmtErr error) *ScriptExecutor {
+		return &ScriptExecutor{
+			Statements: []*StatementExecutor{{
+				Query: "UPDATE accounts SET balance = balance - 1",
+				Fn: func(ctx context.Context) *QueryResult {
+					// Statement must see transaction through context
+					assert.Equal(t, Tx(tx), txFromContext(ctx))
+					return &QueryResult{RowsAffected: 1, ResponseTime: time.Millisecond, Err: stmtErr}
+				},
+			}},
+			Tx: &TxExecutor{
+				Rollback: rollback,
+				BeginFn: func(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+					return tx, nil
+				},
+			},
+		}
+	}
+
+	t.Run("should commit transaction", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+		tx := &fakeTx{}
+
+		NewThread(1, metric, newScript(tx, false, nil), &logger).exec(context.Background(), time.Time{})
+
+		assert.True(t, tx.committed)
+		assert.False(t, tx.rolledBack)
+		assert.Equal(t, int64(0), metric.ErrorsTotal)
+	})
+
+	t.Run("should rollback transaction when configured", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+		tx := &fakeTx{}
+
+		NewThread(1, metric, newScript(tx, true, nil), &logger).exec(context.Background(), time.Time{})
+
+		assert.False(t, tx.committed)
+		assert.True(t, tx.rolledBack)
+	})
+
+	t.Run("should rollback transaction on failed statement", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+		tx := &fakeTx{}
+
+		NewThread(1, metric, newScript(tx, false, assert.AnError), &logger).exec(context.Background(), time.Time{})
+
+		assert.False(t, tx.committed)
+		assert.True(t, tx.rolledBack)
+		assert.Equal(t, int64(1), metric.ErrorsTotal)
+	})
+
+	t.Run("should count serialization failure on commit", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+		tx := &fakeTx{commitErr: &pq.Error{Code: "40001", Message: "could not serialize access"}}
+
+		NewThread(1, metric, newScript(tx, false, nil), &logger).exec(context.Background(), time.Time{})
+
+		assert.True(t, tx.committed)
+		assert.Equal(t, int64(2), metric.QueriesTotal, "failed commit is counted as attempt")
+		assert.Equal(t, int64(1), metric.ErrorsTotal)
+		assert.Equal(t, int64(1), metric.SerializationFailuresTotal)
+	})
+
+	t.Run("should count failed begin", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+		script := newScript(&fakeTx{}, false, nil)
+		script.Tx.BeginFn = func(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+			return nil, assert.AnError
+		}
+
+		NewThread(1, metric, script, &logger).exec(context.Background(), time.Time{})
+
+		assert.Equal(t, int64(1), metric.QueriesTotal)
+		assert.Equal(t, int64(1), metric.ErrorsTotal)
+		assert.Equal(t, 100.0, metric.GetFailedRate())
+	})
+
+	t.Run("should not count begin and commit cancelled by stopped scenario", func(t *testing.T) {
+		metric, err := NewMetric()
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		tx := &fakeTx{commitErr: context.Canceled}
+		script := newScript(tx, false, nil)
+		// Scenario stops while statement is executed
+		stmtFn := script.Statements[0].Fn
+		script.Statements[0].Fn = func(execCtx context.Context) *QueryResult {
+			res := stmtFn(execCtx)
+			cancel()
+			return res
+		}
+
+		NewThread(1, metric, script, &logger).exec(ctx, time.Time{})
+
+		assert.True(t, tx.committed)
+		assert.Equal(t, int64(0), metric.ErrorsTotal)
+
+		script.Tx.BeginFn = func(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+			return nil, context.Canceled
+		}
+		NewThread(1, metric, script, &logger).exec(ctx, time.Time{})
+
+		assert.Equal(t, int64(0), metric.ErrorsTotal)
 	})
 }
 
@@ -337,8 +669,7 @@ func TestScenarioDur_Run_WithoutRampUp(t *testing.T) {
 	// Create real threads with test executors
 	sharedId := NewSharedId()
 	executor := &StatementExecutor{
-		Query:  "SELECT 1",
-		Pacing: 0,
+		Query: "SELECT 1",
 		Fn: func(ctx context.Context) *QueryResult {
 			return &QueryResult{
 				RowsAffected: 1,
@@ -348,7 +679,7 @@ func TestScenarioDur_Run_WithoutRampUp(t *testing.T) {
 		},
 	}
 
-	threads, err := InitThreads(2, sharedId, executor, &logger)
+	threads, err := InitThreads(2, sharedId, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
 	require.NoError(t, err)
 
 	scenario := NewScenarioDur(&logger, cfg, threads, mainMetric)
@@ -382,8 +713,7 @@ func TestScenarioDur_Run_WithRampUp(t *testing.T) {
 
 	sharedId := NewSharedId()
 	executor := &StatementExecutor{
-		Query:  "SELECT 1",
-		Pacing: 0,
+		Query: "SELECT 1",
 		Fn: func(ctx context.Context) *QueryResult {
 			return &QueryResult{
 				RowsAffected: 1,
@@ -393,7 +723,7 @@ func TestScenarioDur_Run_WithRampUp(t *testing.T) {
 		},
 	}
 
-	threads, err := InitThreads(3, sharedId, executor, &logger)
+	threads, err := InitThreads(3, sharedId, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
 	require.NoError(t, err)
 
 	scenario := NewScenarioDur(&logger, cfg, threads, mainMetric)
@@ -413,6 +743,201 @@ func TestScenarioDur_Run_WithRampUp(t *testing.T) {
 	assert.Greater(t, mainMetric.QueriesTotal, int64(0))
 }
 
+func TestScenarioDur_Run_WithStages(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	cfg := &ScenarioConfig{
+		Stages: []*StageConfig{
+			{Target: 3, Duration: 30 * time.Millisecond},
+			{Target: 3, Duration: 30 * time.Millisecond},
+			{Target: 0, Duration: 30 * time.Millisecond},
+		},
+	}
+
+	mainMetric, err := NewMetric()
+	require.NoError(t, err)
+
+	sharedId := NewSharedId()
+	executor := &StatementExecutor{
+		Query: "SELECT 1",
+		Fn: func(ctx context.Context) *QueryResult {
+			time.Sleep(time.Millisecond)
+			return &QueryResult{
+				RowsAffected: 1,
+				ResponseTime: time.Millisecond,
+				Err:          nil,
+			}
+		},
+	}
+
+	threads, err := InitThreads(maxStageTarget(cfg.Stages), sharedId, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+	require.NoError(t, err)
+
+	scenario := NewScenarioDur(&logger, cfg, threads, mainMetric)
+
+	startTime := time.Now()
+	err = scenario.Run(context.Background())
+	duration := time.Since(startTime)
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, duration, stagesDuration(cfg.Stages))
+
+	// Every thread of the pool should have been started
+	for _, th := range threads {
+		assert.Greater(t, th.Metric.IterationsTotal, int64(0))
+	}
+	assert.Greater(t, mainMetric.QueriesTotal, int64(0))
+	assert.Equal(t, int64(0), mainMetric.ErrorsTotal)
+}
+
+func TestScenarioDur_Run_CancelledDuringRampUp(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+	cfg := &ScenarioConfig{
+		Duration: time.Second,
+		Threads:  10,
+		RampUp:   time.Second,
+	}
+
+	mainMetric, err := NewMetric()
+	require.NoError(t, err)
+
+	executor := &StatementExecutor{
+		Query: "SELECT 1",
+		Fn: func(ctx context.Context) *QueryResult {
+			time.Sleep(time.Millisecond)
+			return &QueryResult{RowsAffected: 1, ResponseTime: time.Millisecond}
+		},
+	}
+	threads, err := InitThreads(cfg.Threads, NewSharedId(), &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+	require.NoError(t, err)
+
+	scenario := NewScenarioDur(&logger, cfg, threads, mainMetric)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	err = scenario.Run(ctx)
+
+	// Metrics of already started threads are kept
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Greater(t, mainMetric.QueriesTotal, int64(0))
+	assert.False(t, mainMetric.StopTime.IsZero())
+}
+
+func TestScenarioRate_Run(t *testing.T) {
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	t.Run("should keep configured rate", func(t *testing.T) {
+		cfg := &ScenarioConfig{
+			Duration: 200 * time.Millisecond,
+			Threads:  2,
+			Rate:     100,
+		}
+
+		mainMetric, err := NewMetric()
+		require.NoError(t, err)
+
+		executor := &StatementExecutor{
+			Query: "SELECT 1",
+			Fn: func(ctx context.Context) *QueryResult {
+				return &QueryResult{RowsAffected: 1, ResponseTime: time.Microsecond}
+			},
+		}
+		threads, err := InitThreads(cfg.Threads, NewSharedId(), &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+		require.NoError(t, err)
+
+		scenario := NewScenarioRate(&logger, cfg, threads, mainMetric)
+		err = scenario.Run(context.Background())
+
+		assert.NoError(t, err)
+		// 100 arrivals per second during 200ms
+		assert.InDelta(t, 20, mainMetric.IterationsTotal, 3)
+		assert.Equal(t, mainMetric.IterationsTotal, mainMetric.QueriesTotal)
+		assert.Equal(t, int64(0), mainMetric.DroppedTotal)
+	})
+
+	t.Run("should drop arrivals when workers are busy", func(t *testing.T) {
+		cfg := &ScenarioConfig{
+			Duration: 100 * time.Millisecond,
+			Threads:  1,
+			Rate:     1000,
+		}
+
+		mainMetric, err := NewMetric()
+		require.NoError(t, err)
+
+		executor := &StatementExecutor{
+			Query: "SELECT pg_sleep(1)",
+			Fn: func(ctx context.Context) *QueryResult {
+				time.Sleep(20 * time.Millisecond)
+				return &QueryResult{RowsAffected: 1, ResponseTime: 20 * time.Millisecond}
+			},
+		}
+		threads, err := InitThreads(cfg.Threads, NewSharedId(), &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+		require.NoError(t, err)
+
+		scenario := NewScenarioRate(&logger, cfg, threads, mainMetric)
+		err = scenario.Run(context.Background())
+
+		assert.NoError(t, err)
+		assert.Greater(t, mainMetric.DroppedTotal, int64(0))
+		assert.Greater(t, mainMetric.DelayedTotal, int64(0))
+		assert.Less(t, mainMetric.IterationsTotal, int64(100))
+		// Time in queue is recorded without hdr recorder
+		assert.Greater(t, mainMetric.QueueTd.Count(), uint64(0))
+		assert.GreaterOrEqual(t, quantile(mainMetric.QueueTd, 1), 10*time.Millisecond)
+	})
+
+	t.Run("should stop dispatching when all workers stopped on exhausted feeder", func(t *testing.T) {
+		cfg := &ScenarioConfig{
+			Duration: 10 * time.Second,
+			Threads:  2,
+			Rate:     100,
+		}
+
+		mainMetric, err := NewMetric()
+		require.NoError(t, err)
+
+		executor := &StatementExecutor{
+			Query: "SELECT $1",
+			Fn: func(ctx context.Context) *QueryResult {
+				return &QueryResult{Err: fmt.Errorf("%w: feeder ids", ErrFeederExhausted)}
+			},
+		}
+		threads, err := InitThreads(cfg.Threads, NewSharedId(), &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
+		require.NoError(t, err)
+
+		scenario := NewScenarioRate(&logger, cfg, threads, mainMetric)
+		start := time.Now()
+		err = scenario.Run(context.Background())
+
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second, "scenario ends when its threads stop")
+		assert.Less(t, mainMetric.DroppedTotal, int64(10))
+		assert.Equal(t, int64(0), mainMetric.IterationsTotal)
+	})
+}
+
+func TestExpectedArrivals(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     int
+		rampUp   time.Duration
+		elapsed  time.Duration
+		expected int64
+	}{
+		{name: "constant rate", rate: 500, elapsed: 2 * time.Second, expected: 1000},
+		{name: "scenario start", rate: 500, elapsed: 0, expected: 0},
+		{name: "middle of ramp up", rate: 100, rampUp: 10 * time.Second, elapsed: 5 * time.Second, expected: 125},
+		{name: "end of ramp up", rate: 100, rampUp: 10 * time.Second, elapsed: 10 * time.Second, expected: 500},
+		{name: "after ramp up", rate: 100, rampUp: 10 * time.Second, elapsed: 12 * time.Second, expected: 700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, expectedArrivals(tt.rate, tt.rampUp, tt.elapsed))
+		})
+	}
+}
+
 func TestScenarioIter_Run_WithoutRampUp(t *testing.T) {
 	logger := zerolog.New(zerolog.NewTestWriter(t))
 	cfg := &ScenarioConfig{
@@ -426,8 +951,7 @@ func TestScenarioIter_Run_WithoutRampUp(t *testing.T) {
 
 	sharedId := NewSharedId()
 	executor := &StatementExecutor{
-		Query:  "SELECT 1",
-		Pacing: 0,
+		Query: "SELECT 1",
 		Fn: func(ctx context.Context) *QueryResult {
 			return &QueryResult{
 				RowsAffected: 1,
@@ -437,7 +961,7 @@ func TestScenarioIter_Run_WithoutRampUp(t *testing.T) {
 		},
 	}
 
-	threads, err := InitThreads(2, sharedId, executor, &logger)
+	threads, err := InitThreads(2, sharedId, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
 	require.NoError(t, err)
 
 	scenario := NewScenarioIter(&logger, cfg, threads, mainMetric)
@@ -466,8 +990,7 @@ func TestScenarioIter_Run_WithRampUp(t *testing.T) {
 
 	sharedId := NewSharedId()
 	executor := &StatementExecutor{
-		Query:  "SELECT 1",
-		Pacing: 0,
+		Query: "SELECT 1",
 		Fn: func(ctx context.Context) *QueryResult {
 			return &QueryResult{
 				RowsAffected: 1,
@@ -477,7 +1000,7 @@ func TestScenarioIter_Run_WithRampUp(t *testing.T) {
 		},
 	}
 
-	threads, err := InitThreads(2, sharedId, executor, &logger)
+	threads, err := InitThreads(2, sharedId, &ScriptExecutor{Statements: []*StatementExecutor{executor}}, &logger)
 	require.NoError(t, err)
 
 	scenario := NewScenarioIter(&logger, cfg, threads, mainMetric)
@@ -14,20 +14,33 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func InitThreads(threads int, sharedId *SharedId, statementExecutor *StatementExecutor, logger *zerolog.Logger) ([]*Thread, error) {
+func InitThreads(threads int, sharedId *SharedId, scriptExecutor *ScriptExecutor, logger *zerolog.Logger) ([]*Thread, error) {
 	var (
 		preparedThreads = make([]*Thread, 0)
 	)
 	for i := 0; i < threads; i++ {
-		ts, err := NewMetric()
+		ts, err := NewScriptMetric(len(scriptExecutor.Statements))
 		if err != nil {
 			return nil, err
 		}
-		preparedThreads = append(preparedThreads, NewThread(sharedId.GetId(), ts, statementExecutor, logger))
+		th := NewThread(sharedId.GetId(), ts, scriptExecutor, logger)
+		// Feeders with thread scope give each thread of scenario its own partition of rows
+		th.gen.partition, th.gen.partitions = i, threads
+		preparedThreads = append(preparedThreads, th)
 	}
 	return preparedThreads, nil
 }
 
+// Sum metrics from threads into scenario metric
+func mergeThreadsMetrics(m *Metric, threads []*Thread) error {
+	for _, th := range threads {
+		if err := m.Merge(th.Metric.GetSnapshot()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type SharedId struct {
 	idx int
 	mu  *sync.Mutex
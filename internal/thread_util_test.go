@@ -23,7 +23,7 @@ func TestInitThreads(t *testing.T) {
 		PathToQuery: "path/to/query.sql",
 		Query:       "SELECT * FROM users;",
 	}
-	mockExecutor, err := NewStatementExecutor(context.Background(), time.Second, cfg, nil)
+	mockExecutor, err := NewScriptExecutor(context.Background(), time.Second, []*StatementConfig{cfg}, nil, nil, nil)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,244 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrThresholdsFailed is returned by workflow when at least one threshold is not met
+var ErrThresholdsFailed = errors.New("one or more thresholds failed")
+
+var thresholdRe = regexp.MustCompile(`^\s*([a-z_]+|(?:corrected_)?p[0-9]+(?:\.[0-9]+)?)\s*(<=|>=|<|>)\s*(\S+)\s*$`)
+
+// Prefix of latency metrics measured from intended start, e.g. "corrected_p99"
+const correctedPrefix = "corrected_"
+
+type thresholdKind int
+
+const (
+	thresholdLatency thresholdKind = iota // compared as duration
+	thresholdRate                         // compared as percent
+	thresholdNumber                       // compared as plain number
+)
+
+// Threshold is parsed expression like "p95 < 50ms"
+type Threshold struct {
+	Expr   string
+	Metric string
+	Op     string
+	Value  float64
+	kind   thresholdKind
+
+	// Latency is taken from histogram corrected for coordinated omission
+	corrected bool
+}
+
+// ThresholdResult is outcome of threshold evaluation against scenario metrics
+type ThresholdResult struct {
+	Expr   string `json:"expression"`
+	Actual string `json:"actual"`
+	Passed bool   `json:"passed"`
+}
+
+func ParseThreshold(expr string) (*Threshold, error) {
+	match := thresholdRe.FindStringSubmatch(expr)
+	if match == nil {
+		return nil, fmt.Errorf("invalid threshold: (%s), expected format '<metric> <op> <value>'", expr)
+	}
+	th := &Threshold{Expr: strings.TrimSpace(expr), Metric: match[1], Op: match[2]}
+
+	metric, corrected := strings.CutPrefix(th.Metric, correctedPrefix)
+	kind, err := getThresholdKind(metric)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold: (%s): %w", expr, err)
+	}
+	if corrected && kind != thresholdLatency {
+		return nil, fmt.Errorf("invalid threshold: (%s): only latency can be corrected", expr)
+	}
+	th.kind, th.corrected = kind, corrected
+
+	raw := match[3]
+	switch kind {
+	case thresholdLatency:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold: (%s): %w", expr, err)
+		}
+		th.Value = float64(d)
+	case thresholdRate:
+		th.Value, err = strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold: (%s): %w", expr, err)
+		}
+	default:
+		th.Value, err = strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold: (%s): %w", expr, err)
+		}
+	}
+	return th, nil
+}
+
+func getThresholdKind(metric string) (thresholdKind, error) {
+	switch metric {
+	case "min", "max":
+		return thresholdLatency, nil
+	case "failed_rate", "success_rate":
+		return thresholdRate, nil
+	case "qps", "errors", "queries", "iterations", "rows":
+		return thresholdNumber, nil
+	}
+	if strings.HasPrefix(metric, "p") {
+		p, err := strconv.ParseFloat(metric[1:], 64)
+		if err != nil || p <= 0 || p > 100 {
+			return 0, fmt.Errorf("invalid percentile: (%s)", metric)
+		}
+		return thresholdLatency, nil
+	}
+	return 0, fmt.Errorf("unknown metric: (%s)", metric)
+}
+
+// Evaluate threshold against scenario metric
+func (th *Threshold) Evaluate(m *Metric) *ThresholdResult {
+	return th.evaluate(th.actual(m))
+}
+
+// EvaluateWindow evaluates latency, rate and qps thresholds against window metric
+// and counter thresholds (errors, queries, iterations, rows) against total metric.
+func (th *Threshold) EvaluateWindow(window, total *Metric) *ThresholdResult {
+	if th.isCounter() {
+		return th.evaluate(th.actual(total))
+	}
+	return th.evaluate(th.actual(window))
+}
+
+func (th *Threshold) isCounter() bool {
+	switch th.Metric {
+	case "errors", "queries", "iterations", "rows":
+		return true
+	}
+	return false
+}
+
+func (th *Threshold) evaluate(actual float64) *ThresholdResult {
+	var passed bool
+	switch th.Op {
+	case "<":
+		passed = actual < th.Value
+	case "<=":
+		passed = actual <= th.Value
+	case ">":
+		passed = actual > th.Value
+	case ">=":
+		passed = actual >= th.Value
+	}
+
+	return &ThresholdResult{
+		Expr:   th.Expr,
+		Actual: th.format(actual),
+		Passed: passed,
+	}
+}
+
+func (th *Threshold) actual(m *Metric) float64 {
+	metric := strings.TrimPrefix(th.Metric, correctedPrefix)
+	switch metric {
+	case "min":
+		return th.latency(m, 0)
+	case "max":
+		return th.latency(m, 100)
+	case "failed_rate":
+		return m.GetFailedRate()
+	case "success_rate":
+		return m.GetSuccessRate()
+	case "qps":
+		return m.GetQPS()
+	case "errors":
+		return float64(m.ErrorsTotal)
+	case "queries":
+		return float64(m.QueriesTotal)
+	case "iterations":
+		return float64(m.IterationsTotal)
+	case "rows":
+		return float64(m.RowsAffected)
+	}
+	// Percentile, already validated while parsing
+	p, _ := strconv.ParseFloat(metric[1:], 64)
+	return th.latency(m, p)
+}
+
+// Get latency at percentile p, from HDR histograms when they are enabled, so it matches the report
+func (th *Threshold) latency(m *Metric, p float64) float64 {
+	h := m.Hdr
+	if th.corrected {
+		h = m.CorrectedHdr
+	}
+	if h == nil {
+		return m.Td.Quantile(p / 100)
+	}
+	switch p {
+	case 0:
+		return float64(hdrDuration(h.Min()))
+	case 100:
+		return float64(hdrDuration(h.Max()))
+	}
+	return float64(hdrDuration(h.ValueAtPercentile(p)))
+}
+
+func (th *Threshold) format(v float64) string {
+	switch th.kind {
+	case thresholdLatency:
+		return time.Duration(v).String()
+	case thresholdRate:
+		return fmt.Sprintf("%.2f%%", v)
+	default:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+}
+
+// Evaluate global and scenario thresholds against scenario metric
+func evaluateThresholds(global *ThresholdsConfig, cfg *ScenarioConfig, m *Metric) ([]*ThresholdResult, error) {
+	var exprs []string
+	if global != nil {
+		exprs = append(exprs, global.Checks...)
+	}
+	if cfg.ThresholdsConfig != nil {
+		exprs = append(exprs, cfg.ThresholdsConfig.Checks...)
+	}
+
+	results := make([]*ThresholdResult, 0, len(exprs))
+	for _, expr := range exprs {
+		th, err := ParseThreshold(expr)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, th.Evaluate(m))
+	}
+	return results, nil
+}
+
+// Check if any scenario report has failed threshold
+func thresholdsFailed(cfg *RunConfig) bool {
+	for _, sc := range cfg.WorkflowConfig.Scenarios {
+		if sc.Report == nil {
+			continue
+		}
+		for _, res := range sc.Report.Thresholds {
+			if !res.Passed {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+/*
+LoadHound — Relentless load testing tool for SQL databases.
+Copyright © 2025 Toichuev Ulukbek t.ulukbek01@gmail.com
+
+Licensed under the MIT License.
+*/
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		metric    string
+		op        string
+		value     float64
+		expectErr bool
+	}{
+		{name: "latency percentile", expr: "p95 < 50ms", metric: "p95", op: "<", value: float64(50 * time.Millisecond)},
+		{name: "fractional percentile", expr: "p99.9 <= 1s", metric: "p99.9", op: "<=", value: float64(time.Second)},
+		{name: "max latency without spaces", expr: "max<2s", metric: "max", op: "<", value: float64(2 * time.Second)},
+		{name: "rate with percent", expr: "failed_rate < 1%", metric: "failed_rate", op: "<", value: 1},
+		{name: "rate without percent", expr: "success_rate >= 99.5", metric: "success_rate", op: ">=", value: 99.5},
+		{name: "qps", expr: "qps > 200", metric: "qps", op: ">", value: 200},
+		{name: "corrected percentile", expr: "corrected_p99 < 1s", metric: "corrected_p99", op: "<", value: float64(time.Second)},
+		{name: "corrected max", expr: "corrected_max < 1s", metric: "corrected_max", op: "<", value: float64(time.Second)},
+		{name: "corrected rate", expr: "corrected_failed_rate < 1%", expectErr: true},
+		{name: "unknown metric", expr: "latency < 1s", expectErr: true},
+		{name: "invalid percentile", expr: "p150 < 1s", expectErr: true},
+		{name: "invalid duration", expr: "p95 < 50", expectErr: true},
+		{name: "invalid operator", expr: "qps == 200", expectErr: true},
+		{name: "empty expression", expr: "", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th, err := ParseThreshold(tt.expr)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.metric, th.Metric)
+			assert.Equal(t, tt.op, th.Op)
+			assert.Equal(t, tt.value, th.Value)
+		})
+	}
+}
+
+func TestThreshold_Evaluate(t *testing.T) {
+	metric, err := NewMetric()
+	require.NoError(t, err)
+
+	startTime := time.Now()
+	metric.SetStartTime(startTime)
+	metric.SetStopTime(startTime.Add(time.Second))
+	for i := 0; i < 99; i++ {
+		require.NoError(t, metric.SubmitQueryResult(&QueryResult{ResponseTime: 10 * time.Millisecond}))
+	}
+	require.NoError(t, metric.SubmitQueryResult(&QueryResult{ResponseTime: 10 * time.Millisecond, Err: assert.AnError}))
+
+	tests := []struct {
+		expr   string
+		passed bool
+		actual string
+	}{
+		{expr: "p95 < 50ms", passed: true, actual: "10ms"},
+		{expr: "max > 50ms", passed: false, actual: "10ms"},
+		{expr: "failed_rate < 1%", passed: false, actual: "1.00%"},
+		{expr: "failed_rate <= 1%", passed: true, actual: "1.00%"},
+		{expr: "qps >= 100", passed: true, actual: "100"},
+		{expr: "errors < 1", passed: false, actual: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			th, err := ParseThreshold(tt.expr)
+			require.NoError(t, err)
+
+			res := th.Evaluate(metric)
+			assert.Equal(t, tt.expr, res.Expr)
+			assert.Equal(t, tt.passed, res.Passed)
+			assert.Equal(t, tt.actual, res.Actual)
+		})
+	}
+}
+
+func TestThreshold_EvaluateHdr(t *testing.T) {
+	metric, err := NewMetric()
+	require.NoError(t, err)
+	metric.EnableHdr()
+	// TDigest and HDR histogram differ for the same values, thresholds must use the one in report
+	for i := 1; i <= 100; i++ {
+		require.NoError(t, metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Duration(i) * time.Millisecond, ScheduleDelay: time.Second}))
+	}
+
+	tests := []struct {
+		expr   string
+		actual string
+	}{
+		{expr: "p99 < 1s", actual: hdrDuration(metric.Hdr.ValueAtPercentile(99)).String()},
+		{expr: "max < 1s", actual: hdrDuration(metric.Hdr.Max()).String()},
+		{expr: "corrected_p99 < 1s", actual: hdrDuration(metric.CorrectedHdr.ValueAtPercentile(99)).String()},
+		{expr: "corrected_min < 1s", actual: hdrDuration(metric.CorrectedHdr.Min()).String()},
+	}
+	for _, tt := range tests {
+		th, err := ParseThreshold(tt.expr)
+		require.NoError(t, err)
+		assert.Equal(t, tt.actual, th.Evaluate(metric).Actual, tt.expr)
+	}
+
+	th, err := ParseThreshold("corrected_p50 < 1s")
+	require.NoError(t, err)
+	assert.False(t, th.Evaluate(metric).Passed, "corrected latency includes schedule delay")
+}
+
+func TestEvaluateThresholds(t *testing.T) {
+	metric, err := NewMetric()
+	require.NoError(t, err)
+	require.NoError(t, metric.SubmitQueryResult(&QueryResult{ResponseTime: time.Millisecond}))
+
+	cfg := &RunConfig{
+		ThresholdsConfig: &ThresholdsConfig{Checks: []string{"p95 < 50ms"}},
+		WorkflowConfig: &WorkflowConfig{Scenarios: []*ScenarioConfig{{
+			ThresholdsConfig: &ThresholdsConfig{Checks: []string{"queries > 10"}},
+			Report:           &Report{},
+		}}},
+	}
+	sc := cfg.WorkflowConfig.Scenarios[0]
+
+	results, err := evaluateThresholds(cfg.ThresholdsConfig, sc, metric)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+
+	sc.Report.Thresholds = results
+	assert.True(t, thresholdsFailed(cfg))
+
+	sc.Report.Thresholds = results[:1]
+	assert.False(t, thresholdsFailed(cfg))
+}
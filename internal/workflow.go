@@ -9,8 +9,12 @@ package internal
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -19,6 +23,9 @@ import (
 
 const rampUpMin time.Duration = 10 * time.Millisecond
 
+// ErrInterrupted is returned by workflow when test was stopped by signal, partial report is generated anyway
+var ErrInterrupted = errors.New("test interrupted")
+
 type Scenario interface {
 	Run(ctx context.Context) error
 }
@@ -49,9 +56,31 @@ func (w *Workflow) Run(ctx context.Context) error {
 		}
 	}()
 
+	// Get optional exporter of live metrics and raw sample log, both observe every query
+	var (
+		exporter  *PromExporter
+		observers []ObserverProvider
+	)
+	if w.cfg.OutputConfig != nil && w.cfg.OutputConfig.PrometheusConfig != nil {
+		exporter = NewPromExporter(w.cfg.OutputConfig.PrometheusConfig, w.logger)
+		observers = append(observers, exporter)
+	}
+	if w.cfg.OutputConfig != nil && w.cfg.OutputConfig.SamplesConfig != nil {
+		samples, err := NewSampleLog(w.cfg.OutputConfig.SamplesConfig, w.logger)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := samples.Close(); err != nil {
+				w.logger.Error().Err(err).Msg("Failed to close sample log")
+			}
+		}()
+		observers = append(observers, samples)
+	}
+
 	cfgs := w.cfg.WorkflowConfig.Scenarios
 	w.logger.Info().Int("scenarios_count", len(cfgs)).Msg("Initializing scenarios")
-	scenarios, scMetrics, closers, err := initScenarios(ctx, w.logger, cfgs, client)
+	scenarios, scMetrics, monitors, closers, err := initScenarios(ctx, w.logger, w.cfg, cfgs, client, observers)
 	if err != nil {
 		return err
 	}
@@ -63,69 +92,224 @@ func (w *Workflow) Run(ctx context.Context) error {
 		}
 	}()
 
-	g, ctx := errgroup.WithContext(ctx)
+	if exporter != nil {
+		if err := exporter.Start(); err != nil {
+			return err
+		}
+		defer func() {
+			if err := exporter.Close(); err != nil {
+				w.logger.Error().Err(err).Msg("Failed to stop prometheus listener")
+			}
+		}()
+	}
+
+	if w.cfg.OutputConfig != nil && w.cfg.OutputConfig.LineSinkConfig != nil {
+		sink, err := NewLineSink(w.cfg.OutputConfig.LineSinkConfig, reportPercentiles(w.cfg), w.logger)
+		if err != nil {
+			return err
+		}
+		// Sink is closed after monitors are stopped, so the last aggregates are written
+		defer func() {
+			if err := sink.Close(); err != nil {
+				w.logger.Error().Err(err).Msg("Failed to close line protocol sink")
+			}
+		}()
+		for _, mon := range monitors {
+			if err := mon.SetLineSink(sink); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Pool is shared by all scenarios, so its stats are sampled once for the run
+	pool := NewPoolSampler(client.DB.Stats, timeseriesInterval(w.cfg))
+	for _, mon := range monitors {
+		mon.SetPoolSampler(pool)
+	}
+	if exporter != nil {
+		exporter.SetPoolStats(pool.Current)
+	}
+
+	// Monitors print progress and cancel run context with AbortError cause when abort threshold is not met
+	runCtx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+
+	// Monitors are stopped only after all scenarios are finished to record the last results
+	monitorCtx, stopMonitors := context.WithCancel(context.WithoutCancel(ctx))
+	var monitorsWg sync.WaitGroup
+	monitorsWg.Add(1)
+	go func() {
+		defer monitorsWg.Done()
+		pool.Run(monitorCtx)
+	}()
+	for _, mon := range monitors {
+		monitorsWg.Add(1)
+		go func() {
+			defer monitorsWg.Done()
+			mon.Run(monitorCtx, abort)
+		}()
+	}
+
+	g, gCtx := errgroup.WithContext(runCtx)
 	startAt := time.Now()
 	for _, sc := range scenarios {
 		g.Go(func() error {
-			return sc.Run(ctx)
+			return sc.Run(gCtx)
 		})
 	}
-	if err := g.Wait(); err != nil {
+	err = g.Wait()
+	stopMonitors()
+	monitorsWg.Wait()
+	for idx, mon := range monitors {
+		scMetrics[idx].Timeseries = mon.Timeseries
+	}
+	w.cfg.PoolStats = pool.Report()
+
+	var abortErr *AbortError
+	aborted := errors.As(context.Cause(runCtx), &abortErr)
+	// Parent context is cancelled only by interrupt signal
+	interrupted := ctx.Err() != nil
+	if err != nil && !aborted && !interrupted {
 		return fmt.Errorf("one or more scenarios failed: %w", err)
 	}
 
-	w.logger.Info().Str("total_duration", time.Since(startAt).String()).Msg("All scenarios completed successfully")
-	return GenerateReport(w.cfg, scMetrics)
+	switch {
+	case aborted:
+		w.logger.Warn().Str("total_duration", time.Since(startAt).String()).Str("reason", abortErr.Reason).Msg("Test aborted")
+		for _, m := range scMetrics {
+			m.AbortReason = abortErr.Reason
+		}
+	case interrupted:
+		w.logger.Warn().Str("total_duration", time.Since(startAt).String()).Msg("Test interrupted, generating partial report")
+		for _, m := range scMetrics {
+			m.Interrupted = true
+		}
+	default:
+		w.logger.Info().Str("total_duration", time.Since(startAt).String()).Msg("All scenarios completed successfully")
+	}
+	if err := GenerateReport(w.cfg, scMetrics); err != nil {
+		return err
+	}
+	if aborted {
+		return fmt.Errorf("%w: %w", ErrThresholdsFailed, abortErr)
+	}
+	if interrupted {
+		return ErrInterrupted
+	}
+	if thresholdsFailed(w.cfg) {
+		return ErrThresholdsFailed
+	}
+	return nil
 }
 
-func initScenarios(ctx context.Context, logger *zerolog.Logger, cfgs []*ScenarioConfig, client *SQLClient) ([]Scenario, []*Metric, []func() error, error) {
+func initScenarios(ctx context.Context, logger *zerolog.Logger, runCfg *RunConfig, cfgs []*ScenarioConfig, client *SQLClient, observers []ObserverProvider) ([]Scenario, []*Metric, []*Monitor, []func() error, error) {
 	closers := make([]func() error, 0)
 	sharedId := NewSharedId()
 
 	scenarios := make([]Scenario, 0)
 	scenariosMetrics := make([]*Metric, 0)
+	monitors := make([]*Monitor, 0)
 
 	logger.Info().Int("scenarios_count", len(cfgs)).Msg("Initializing scenarios")
 	for idx, cfg := range cfgs {
 		// Init new logger for scenario from base logger
 		scLogger := logger.With().Str("scenario_name", cfg.Name).Int("scenario_id", idx).Logger()
 
-		// Get statement for each scenario
-		statementExecutor, err := NewStatementExecutor(ctx, cfg.Pacing, cfg.StatementConfig, client)
+		// Get statements script for each scenario
+		statements := cfg.GetStatements()
+		feeders, err := LoadFeeders(cfg.Feeders)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to create statement executor: %w", err)
+			return nil, nil, nil, nil, err
+		}
+		scriptExecutor, err := NewScriptExecutor(ctx, cfg.Pacing, statements, client, feeders, NewSequences())
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("failed to create statement executor: %w", err)
+		}
+		closers = append(closers, scriptExecutor.Close)
+		hdr := hdrEnabled(runCfg)
+		scriptExecutor.CorrectOmission = hdr
+		if cfg.TxConfig != nil {
+			if scriptExecutor.Tx, err = NewTxExecutor(cfg.TxConfig, client); err != nil {
+				return nil, nil, nil, nil, err
+			}
 		}
-		closers = append(closers, statementExecutor.Close)
 
 		// Get prepared threads list and thread metric object linked each thread
-		pth, err := InitThreads(cfg.Threads, sharedId, statementExecutor, &scLogger)
+		// For multi-stage profile prepare as many threads as the highest stage target
+		threads := cfg.Threads
+		if len(cfg.Stages) > 0 {
+			threads = maxStageTarget(cfg.Stages)
+		}
+		pth, err := InitThreads(threads, sharedId, scriptExecutor, &scLogger)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
 		}
 		if pth == nil {
-			return nil, nil, nil, errors.New("failed to init threads")
+			return nil, nil, nil, nil, errors.New("failed to init threads")
+		}
+		if seed := scenarioSeed(runCfg.WorkflowConfig, cfg); seed != nil {
+			for _, th := range pth {
+				th.gen.Seed(*seed, idx)
+			}
+			scLogger.Debug().Uint64("seed", *seed).Msg("Random query arguments are seeded")
+		}
+
+		for _, provider := range observers {
+			scriptExecutor.Observers = append(scriptExecutor.Observers, provider.AddScenario(cfg.Name, scriptExecutor.Statements, pth))
 		}
 
 		scLogger.Debug().Int("threads_initialized", len(pth)).Str("pacing", cfg.Pacing.String()).Msg("Threads initialized successfully")
 
 		// Create metric for scenario
-		m, err := NewMetric()
+		m, err := NewScriptMetric(len(statements))
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, nil, err
+		}
+		if hdr {
+			m.EnableHdr()
+			for _, th := range pth {
+				th.Metric.EnableHdr()
+			}
 		}
 		// Create scenario
 		var sc Scenario
-		if cfg.Duration > 0 {
+		if cfg.Duration > 0 || len(cfg.Stages) > 0 {
 			sc = NewScenarioDur(&scLogger, cfg, pth, m)
 		}
 		if cfg.Iterations > 0 {
 			sc = NewScenarioIter(&scLogger, cfg, pth, m)
 		}
+		if cfg.Rate > 0 {
+			sc = NewScenarioRate(&scLogger, cfg, pth, m)
+		}
+
+		// Create monitor for scenario, it records time-series, prints progress and checks abort thresholds
+		mon, err := NewMonitor(&scLogger, runCfg, cfg, pth)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
 
 		scenarios = append(scenarios, sc)
 		scenariosMetrics = append(scenariosMetrics, m)
+		monitors = append(monitors, mon)
 	}
-	return scenarios, scenariosMetrics, closers, nil
+	return scenarios, scenariosMetrics, monitors, closers, nil
+}
+
+// Get seed of scenario random generators, nil if values must differ between runs
+func scenarioSeed(workflow *WorkflowConfig, cfg *ScenarioConfig) *uint64 {
+	if cfg.Seed != nil {
+		return cfg.Seed
+	}
+	if workflow != nil {
+		return workflow.Seed
+	}
+	return nil
+}
+
+// Check if response times are recorded into HDR histograms with coordinated omission correction
+func hdrEnabled(cfg *RunConfig) bool {
+	return cfg.OutputConfig != nil && cfg.OutputConfig.ReportConfig != nil && cfg.OutputConfig.ReportConfig.Recorder == recorderHdr
 }
 
 func (w *Workflow) getSQLClient(ctx context.Context) (*SQLClient, error) {
@@ -153,11 +337,171 @@ func calculateRampUpInterval(rampUp time.Duration, threads int) time.Duration {
 	return interval
 }
 
-type StatementExecutor struct {
-	Query      string
-	Fn         ExecFunc
+// Get total duration of multi-stage profile
+func stagesDuration(stages []*StageConfig) time.Duration {
+	var total time.Duration
+	for _, st := range stages {
+		total += st.Duration
+	}
+	return total
+}
+
+// Get the highest thread count of multi-stage profile
+func maxStageTarget(stages []*StageConfig) int {
+	var peak int
+	for _, st := range stages {
+		if st.Target > peak {
+			peak = st.Target
+		}
+	}
+	return peak
+}
+
+// Get number of threads which must be active at elapsed time since profile start.
+// Between stages the count changes linearly from previous target to the next one.
+func stageTargetAt(stages []*StageConfig, elapsed time.Duration) int {
+	from := 0
+	for _, st := range stages {
+		if elapsed < st.Duration {
+			progress := float64(elapsed) / float64(st.Duration)
+			return from + int(math.Round(float64(st.Target-from)*progress))
+		}
+		elapsed -= st.Duration
+		from = st.Target
+	}
+	return from
+}
+
+// ScriptExecutor executes ordered list of statements as one thread iteration
+type ScriptExecutor struct {
+	Statements []*StatementExecutor
 	Pacing     time.Duration
-	stmtClient *PreparedStatement
+	Tx         *TxExecutor     // Optional, wraps iteration into transaction
+	Observers  []QueryObserver // Optional, receive every query result, e.g. live metrics exporters
+
+	// Record iterations missed by pacing while query stalled and how late arrivals start,
+	// used for coordinated omission correction
+	CorrectOmission bool
+}
+
+const (
+	txEndCommit   = "commit"
+	txEndRollback = "rollback"
+)
+
+// TxExecutor begins transaction for each iteration and decides how to end it
+type TxExecutor struct {
+	Options  *sql.TxOptions
+	Rollback bool // Rollback instead of commit when all statements succeeded
+	BeginFn  func(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+}
+
+func NewTxExecutor(cfg *TxConfig, client *SQLClient) (*TxExecutor, error) {
+	opts, err := getTxOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &TxExecutor{
+		Options:  opts,
+		Rollback: cfg.End == txEndRollback,
+		BeginFn:  client.BeginTx,
+	}, nil
+}
+
+func (txExec *TxExecutor) Begin(ctx context.Context) (Tx, error) {
+	return txExec.BeginFn(ctx, txExec.Options)
+}
+
+// Map transaction config into driver options
+func getTxOptions(cfg *TxConfig) (*sql.TxOptions, error) {
+	opts := &sql.TxOptions{ReadOnly: cfg.ReadOnly}
+	switch strings.ToLower(strings.TrimSpace(cfg.IsolationLevel)) {
+	case "", "default":
+		opts.Isolation = sql.LevelDefault
+	case "read_uncommitted":
+		opts.Isolation = sql.LevelReadUncommitted
+	case "read_committed":
+		opts.Isolation = sql.LevelReadCommitted
+	case "repeatable_read":
+		opts.Isolation = sql.LevelRepeatableRead
+	case "serializable":
+		opts.Isolation = sql.LevelSerializable
+	default:
+		return nil, fmt.Errorf("unknown transaction isolation_level: (%s)", cfg.IsolationLevel)
+	}
+	return opts, nil
+}
+
+func (scriptExec *ScriptExecutor) Close() error {
+	var errs []error
+	for _, stmtExec := range scriptExec.Statements {
+		if err := stmtExec.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func NewScriptExecutor(ctx context.Context, pacing time.Duration, cfgs []*StatementConfig, client *SQLClient, feeders map[string]*Feeder, seqs *Sequences) (*ScriptExecutor, error) {
+	scriptExec := &ScriptExecutor{
+		Statements: make([]*StatementExecutor, 0, len(cfgs)),
+		Pacing:     pacing,
+	}
+	for idx, cfg := range cfgs {
+		stmtExec, err := NewStatementExecutor(ctx, cfg, client, feeders, seqs)
+		if err != nil {
+			// Release statements which are already prepared
+			if closeErr := scriptExec.Close(); closeErr != nil {
+				err = errors.Join(err, closeErr)
+			}
+			return nil, err
+		}
+		stmtExec.Name = statementName(cfg, idx)
+		scriptExec.Statements = append(scriptExec.Statements, stmtExec)
+	}
+	return scriptExec, nil
+}
+
+// Get statement label for reports, fallback to position in script
+func statementName(cfg *StatementConfig, idx int) string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return fmt.Sprintf("statement_%d", idx+1)
+}
+
+type StatementExecutor struct {
+	Name          string
+	Query         string
+	Fn            ExecFunc
+	Timeout       time.Duration // Deadline of each query, 0 means no deadline
+	SlowThreshold time.Duration // 0 means queries are never marked as slow
+	stmtClient    *PreparedStatement
+}
+
+// Exec runs statement query with its own deadline if timeout is set.
+// Query which exceeded timeout gets error wrapping context.DeadlineExceeded,
+// so it's classified as timeout whatever the driver returned.
+func (stmtExec *StatementExecutor) Exec(ctx context.Context) *QueryResult {
+	if stmtExec.Timeout <= 0 {
+		return stmtExec.markSlow(stmtExec.Fn(ctx))
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, stmtExec.Timeout)
+	defer cancel()
+
+	queryResult := stmtExec.Fn(queryCtx)
+	timedOut := ctx.Err() == nil && errors.Is(queryCtx.Err(), context.DeadlineExceeded)
+	if queryResult.Err != nil && timedOut && !errors.Is(queryResult.Err, context.DeadlineExceeded) {
+		queryResult.Err = fmt.Errorf("query timeout %s exceeded: %w: %w", stmtExec.Timeout, context.DeadlineExceeded, queryResult.Err)
+	}
+	return stmtExec.markSlow(queryResult)
+}
+
+func (stmtExec *StatementExecutor) markSlow(queryResult *QueryResult) *QueryResult {
+	if stmtExec.SlowThreshold > 0 && queryResult.ResponseTime > stmtExec.SlowThreshold {
+		queryResult.Slow = true
+	}
+	return queryResult
 }
 
 func (stmtExec *StatementExecutor) Close() error {
@@ -167,28 +511,30 @@ func (stmtExec *StatementExecutor) Close() error {
 	return nil
 }
 
-func NewStatementExecutor(ctx context.Context, pacing time.Duration, cfg *StatementConfig, client *SQLClient) (*StatementExecutor, error) {
-	execFunc, stmtClient, err := NewExecFunc(ctx, client, cfg.Query, cfg.Args)
+func NewStatementExecutor(ctx context.Context, cfg *StatementConfig, client *SQLClient, feeders map[string]*Feeder, seqs *Sequences) (*StatementExecutor, error) {
+	execFunc, stmtClient, err := NewExecFunc(ctx, client, cfg.Query, cfg.Args, feeders, seqs)
 	if err != nil {
 		return nil, err
 	}
 	var stmtExec = &StatementExecutor{
-		Query:      cfg.Query,
-		Fn:         execFunc,
-		Pacing:     pacing,
-		stmtClient: stmtClient,
+		Name:          cfg.Name,
+		Query:         cfg.Query,
+		Fn:            execFunc,
+		Timeout:       cfg.Timeout,
+		SlowThreshold: cfg.SlowThreshold,
+		stmtClient:    stmtClient,
 	}
 	return stmtExec, nil
 }
 
 type ExecFunc func(ctx context.Context) *QueryResult
 
-func NewExecFunc(ctx context.Context, client *SQLClient, query, args string) (ExecFunc, *PreparedStatement, error) {
+func NewExecFunc(ctx context.Context, client *SQLClient, query, args string, feeders map[string]*Feeder, seqs *Sequences) (ExecFunc, *PreparedStatement, error) {
 	// If query is parametrizied
 	// Create prepared statement
 	// Get built-in functions
 	if args != "" {
-		generators, err := GetGenerators(args)
+		generators, err := GetGenerators(args, feeders, seqs)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -214,13 +560,19 @@ func getStmtFunc(s *PreparedStatement, query string, generators []GeneratorFunc)
 	queryType := DetectQueryType(query)
 	if queryType == "exec" {
 		return func(ctx context.Context) *QueryResult {
-			args := getArgs(generators)
+			args, err := getArgs(genFromContext(ctx), generators)
+			if err != nil {
+				return &QueryResult{Query: query, StartTime: time.Now(), Err: err}
+			}
 			return s.StmtExecContext(ctx, query, args...)
 		}, nil
 	}
 	if queryType == "query" {
 		return func(ctx context.Context) *QueryResult {
-			args := getArgs(generators)
+			args, err := getArgs(genFromContext(ctx), generators)
+			if err != nil {
+				return &QueryResult{Query: query, StartTime: time.Now(), Err: err}
+			}
 			return s.StmtQueryContext(ctx, query, args...)
 		}, nil
 	}
@@ -243,11 +595,14 @@ func getExecFunc(client *SQLClient, query string) (ExecFunc, error) {
 	return nil, errors.New("unknown query type")
 }
 
-// Get list of values for SQL query from functions
-func getArgs(generators []GeneratorFunc) []any {
+// Get list of values for SQL query from functions, error is returned if feeder failed to take row
+func getArgs(gc *GenContext, generators []GeneratorFunc) ([]any, error) {
 	args := make([]any, len(generators))
 	for idx, fn := range generators {
-		args[idx] = fn()
+		args[idx] = fn(gc)
+	}
+	if gc.err != nil {
+		return nil, gc.err
 	}
-	return args
+	return args, nil
 }
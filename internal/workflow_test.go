@@ -8,6 +8,9 @@ Licensed under the MIT License.
 package internal
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -35,7 +38,7 @@ func TestGetArgs(t *testing.T) {
 		{
 			name: "single generator - bool",
 			generators: []GeneratorFunc{
-				func() any { return true },
+				func(*GenContext) any { return true },
 			},
 			wantLen: 1,
 			validate: func(t *testing.T, args []any) {
@@ -45,7 +48,7 @@ func TestGetArgs(t *testing.T) {
 		{
 			name: "single generator - int",
 			generators: []GeneratorFunc{
-				func() any { return 42 },
+				func(*GenContext) any { return 42 },
 			},
 			wantLen: 1,
 			validate: func(t *testing.T, args []any) {
@@ -55,7 +58,7 @@ func TestGetArgs(t *testing.T) {
 		{
 			name: "single generator - string",
 			generators: []GeneratorFunc{
-				func() any { return "test" },
+				func(*GenContext) any { return "test" },
 			},
 			wantLen: 1,
 			validate: func(t *testing.T, args []any) {
@@ -65,10 +68,10 @@ func TestGetArgs(t *testing.T) {
 		{
 			name: "multiple generators - mixed types",
 			generators: []GeneratorFunc{
-				func() any { return 42 },
-				func() any { return "hello" },
-				func() any { return true },
-				func() any { return 3.14 },
+				func(*GenContext) any { return 42 },
+				func(*GenContext) any { return "hello" },
+				func(*GenContext) any { return true },
+				func(*GenContext) any { return 3.14 },
 			},
 			wantLen: 4,
 			validate: func(t *testing.T, args []any) {
@@ -81,9 +84,9 @@ func TestGetArgs(t *testing.T) {
 		{
 			name: "multiple generators - same type",
 			generators: []GeneratorFunc{
-				func() any { return 1 },
-				func() any { return 2 },
-				func() any { return 3 },
+				func(*GenContext) any { return 1 },
+				func(*GenContext) any { return 2 },
+				func(*GenContext) any { return 3 },
 			},
 			wantLen: 3,
 			validate: func(t *testing.T, args []any) {
@@ -95,9 +98,9 @@ func TestGetArgs(t *testing.T) {
 		{
 			name: "generators with nil values",
 			generators: []GeneratorFunc{
-				func() any { return nil },
-				func() any { return "not nil" },
-				func() any { return nil },
+				func(*GenContext) any { return nil },
+				func(*GenContext) any { return "not nil" },
+				func(*GenContext) any { return nil },
 			},
 			wantLen: 3,
 			validate: func(t *testing.T, args []any) {
@@ -110,7 +113,8 @@ func TestGetArgs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getArgs(tt.generators)
+			got, err := getArgs(NewGenContext(1, 0, 1), tt.generators)
+			require.NoError(t, err)
 
 			assert.Len(t, got, tt.wantLen)
 			if tt.validate != nil {
@@ -201,6 +205,64 @@ func TestCalculateRampUpInterval(t *testing.T) {
 	}
 }
 
+func TestStageTargetAt(t *testing.T) {
+	stages := []*StageConfig{
+		{Target: 10, Duration: 10 * time.Second},
+		{Target: 10, Duration: 10 * time.Second},
+		{Target: 20, Duration: 10 * time.Second},
+		{Target: 0, Duration: 10 * time.Second},
+	}
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		expected int
+	}{
+		{name: "profile start", elapsed: 0, expected: 0},
+		{name: "middle of ramp up", elapsed: 5 * time.Second, expected: 5},
+		{name: "hold stage", elapsed: 15 * time.Second, expected: 10},
+		{name: "middle of step up", elapsed: 25 * time.Second, expected: 15},
+		{name: "middle of ramp down", elapsed: 35 * time.Second, expected: 10},
+		{name: "after profile end", elapsed: time.Minute, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stageTargetAt(stages, tt.elapsed))
+		})
+	}
+
+	assert.Equal(t, 40*time.Second, stagesDuration(stages))
+	assert.Equal(t, 20, maxStageTarget(stages))
+}
+
+func TestGetTxOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *TxConfig
+		expected  *sql.TxOptions
+		expectErr bool
+	}{
+		{name: "default isolation", cfg: &TxConfig{}, expected: &sql.TxOptions{Isolation: sql.LevelDefault}},
+		{name: "read committed", cfg: &TxConfig{IsolationLevel: "read_committed"}, expected: &sql.TxOptions{Isolation: sql.LevelReadCommitted}},
+		{name: "repeatable read", cfg: &TxConfig{IsolationLevel: "repeatable_read"}, expected: &sql.TxOptions{Isolation: sql.LevelRepeatableRead}},
+		{name: "read only serializable", cfg: &TxConfig{IsolationLevel: "Serializable", ReadOnly: true}, expected: &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}},
+		{name: "unknown isolation", cfg: &TxConfig{IsolationLevel: "snapshot"}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getTxOptions(tt.cfg)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
 func TestNewWorkflow(t *testing.T) {
 	// Create a test logger
 	logger := zerolog.Nop() // No-op logger for testing
@@ -360,17 +422,63 @@ func TestNewWorkflow(t *testing.T) {
 	}
 }
 
+func TestStatementExecutorExec(t *testing.T) {
+	blocking := func(ctx context.Context) *QueryResult {
+		start := time.Now()
+		<-ctx.Done()
+		return &QueryResult{StartTime: start, ResponseTime: time.Since(start), Err: errors.New("pq: canceling statement due to user request")}
+	}
+
+	t.Run("timeout is classified", func(t *testing.T) {
+		stmtExec := &StatementExecutor{Name: "select", Fn: blocking, Timeout: 20 * time.Millisecond}
+
+		got := stmtExec.Exec(context.Background())
+		require.Error(t, got.Err)
+		assert.Equal(t, "timeout", errorClass(got.Err))
+		assert.Contains(t, got.Err.Error(), "canceling statement")
+	})
+	t.Run("parent cancel is not timeout", func(t *testing.T) {
+		stmtExec := &StatementExecutor{Name: "select", Fn: blocking, Timeout: time.Minute}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		got := stmtExec.Exec(ctx)
+		require.Error(t, got.Err)
+		assert.NotEqual(t, "timeout", errorClass(got.Err))
+	})
+	t.Run("slow query is marked", func(t *testing.T) {
+		stmtExec := &StatementExecutor{
+			Name:          "select",
+			Fn:            func(ctx context.Context) *QueryResult { return &QueryResult{ResponseTime: 50 * time.Millisecond} },
+			SlowThreshold: 10 * time.Millisecond,
+		}
+		assert.True(t, stmtExec.Exec(context.Background()).Slow)
+
+		stmtExec.SlowThreshold = 0
+		assert.False(t, stmtExec.Exec(context.Background()).Slow)
+	})
+}
+
+func TestScenarioSeed(t *testing.T) {
+	global, own := uint64(1), uint64(2)
+
+	assert.Nil(t, scenarioSeed(&WorkflowConfig{}, &ScenarioConfig{}))
+	assert.Equal(t, &global, scenarioSeed(&WorkflowConfig{Seed: &global}, &ScenarioConfig{}))
+	assert.Equal(t, &own, scenarioSeed(&WorkflowConfig{Seed: &global}, &ScenarioConfig{Seed: &own}))
+}
+
 // Benchmark tests for performance validation
 func BenchmarkGetArgs(b *testing.B) {
 	generators := []GeneratorFunc{
-		func() any { return RandIntRange(1, 100) },
-		func() any { return RandStringInRange(5, 10) },
-		func() any { return RandBool() },
-		func() any { return RandUUID() },
+		func(gc *GenContext) any { return RandIntRange(gc.Rand, 1, 100) },
+		func(gc *GenContext) any { return RandStringInRange(gc.Rand, 5, 10) },
+		func(gc *GenContext) any { return RandBool(gc.Rand) },
+		func(gc *GenContext) any { return RandUUID(gc.Rand) },
 	}
 
+	gc := NewGenContext(1, 0, 1)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		getArgs(generators)
+		_, _ = getArgs(gc, generators)
 	}
 }
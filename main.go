@@ -9,6 +9,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -23,15 +24,30 @@ import (
 
 const version string = "v0.2.0"
 
+const (
+	// Exit code returned when test finished, but some thresholds were not met
+	exitCodeThresholds = 99
+	// Exit code returned when results are worse than baseline beyond tolerance
+	exitCodeRegression = 98
+	// Exit code returned when test was stopped by signal
+	exitCodeInterrupted = 130
+)
+
 var (
 	runFlag     = flag.String("run", "", "Path to *.toml file with test configuration")
 	versionFlag = flag.Bool("version", false, "Get LoadHound current version")
 	signals     = []os.Signal{os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT}
+
+	baselineFlag       = flag.String("baseline", "", "Path to JSON report to compare results with")
+	compareFlag        = flag.String("compare", "", "Path to JSON report to compare with baseline without running test")
+	toleranceFlag      = flag.Float64("tolerance", internal.DefaultTolerance, "Allowed degradation of qps and response times against baseline, in percent")
+	errorToleranceFlag = flag.Float64("error-tolerance", internal.DefaultErrorTolerance, "Allowed growth of failed rate against baseline, in percentage points")
 )
 
 func main() {
-	globalCtx, globalStop := signal.NotifyContext(context.Background(), signals...)
+	globalCtx, globalStop := context.WithCancel(context.Background())
 	defer globalStop()
+	go handleSignals(globalStop)
 
 	flag.Usage = usage
 	flag.Parse()
@@ -46,6 +62,30 @@ func main() {
 		return
 	}
 
+	// Read baseline before the test to fail fast on invalid file
+	var baseline []*internal.ScenarioReport
+	if *baselineFlag != "" {
+		var err error
+		if baseline, err = internal.ReadReportFile(*baselineFlag); err != nil {
+			fatal(err)
+		}
+	}
+
+	// Compare stored report with baseline without running test
+	if *compareFlag != "" {
+		if baseline == nil {
+			fatal(errors.New("-compare requires -baseline"))
+		}
+		current, err := internal.ReadReportFile(*compareFlag)
+		if err != nil {
+			fatal(err)
+		}
+		if err := compare(baseline, current); err != nil {
+			exitOnCompare(err)
+		}
+		return
+	}
+
 	// Get configuration from file
 	cfg, err := internal.GetConfig(*runFlag)
 	if err != nil {
@@ -65,10 +105,66 @@ func main() {
 	workflow := internal.NewWorkflow(cfg, logger)
 
 	// Run workflow
-	if err := workflow.Run(globalCtx); err != nil {
-		logger.Error().Err(err).Msg("Get error from workflow")
-		fatal(err)
+	runErr := workflow.Run(globalCtx)
+	if runErr != nil && !errors.Is(runErr, internal.ErrThresholdsFailed) {
+		if errors.Is(runErr, internal.ErrInterrupted) {
+			fmt.Fprintln(os.Stderr, runErr)
+			os.Exit(exitCodeInterrupted)
+		}
+		logger.Error().Err(runErr).Msg("Get error from workflow")
+		fatal(runErr)
+	}
+
+	// Compare results with baseline, thresholds exit code takes precedence
+	var compareErr error
+	if baseline != nil {
+		compareErr = compare(baseline, internal.GetScenarioReports(cfg))
 	}
+	if runErr != nil {
+		fmt.Fprintln(os.Stderr, runErr)
+		os.Exit(exitCodeThresholds)
+	}
+	if compareErr != nil {
+		exitOnCompare(compareErr)
+	}
+}
+
+func compare(baseline, current []*internal.ScenarioReport) error {
+	comparisons, err := internal.CompareReports(baseline, current, &internal.CompareConfig{
+		Tolerance:      *toleranceFlag,
+		ErrorTolerance: *errorToleranceFlag,
+	})
+	if err != nil {
+		return err
+	}
+	internal.PrintComparison(os.Stdout, baseline, current, comparisons)
+	if internal.HasRegression(comparisons) {
+		return internal.ErrRegression
+	}
+	return nil
+}
+
+func exitOnCompare(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	if errors.Is(err, internal.ErrRegression) {
+		os.Exit(exitCodeRegression)
+	}
+	os.Exit(1)
+}
+
+// The first signal stops the test gracefully and lets workflow write partial report,
+// the second one forces immediate exit.
+func handleSignals(stop context.CancelFunc) {
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, signals...)
+
+	sig := <-sigCh
+	fmt.Fprintf(os.Stderr, "\nReceived %s, stopping test and generating report. Send signal again to force exit\n", sig)
+	stop()
+
+	sig = <-sigCh
+	fmt.Fprintf(os.Stderr, "Received %s, forced exit\n", sig)
+	os.Exit(exitCodeInterrupted)
 }
 
 func fatal(err error) {
@@ -81,7 +177,15 @@ func usage() {
   -run
       Path to your *.toml file for running test
   -version
-      Get LoadHound version`
+      Get LoadHound version
+  -baseline
+      Path to JSON report to compare results with, used with -run or -compare
+  -compare
+      Path to JSON report to compare with -baseline without running test
+  -tolerance
+      Allowed degradation of qps and response times against baseline, in percent (default 10)
+  -error-tolerance
+      Allowed growth of failed rate against baseline, in percentage points (default 1)`
 	fmt.Println(usage)
 }
 